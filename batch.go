@@ -0,0 +1,92 @@
+package tmux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Run several tmux commands in one round trip, and return the output of each,
+// in the same order as cmds. This is much faster than calling [Runner.Run] for
+// each command when there are many of them, like when setting up a layout.
+//
+// All of cmds are written to tmux at once, so every command runs, even if an
+// earlier one fails. If any fail, the error returned is the [CommandError] for
+// the first which did, whose Command field says which it was, along with the
+// output of the others. Each of cmds must be a single command, on one line:
+// tmux replies separately to each line, and to each command in a line like
+// "a ; b", which would throw off which reply goes with which command.
+func (r *Runner) RunBatch(cmds []string) ([]string, error) {
+	var buf strings.Builder
+	for _, cmd := range cmds {
+		if strings.Contains(cmd, "\n") {
+			return nil, fmt.Errorf("expected command '%s' to be on one line", cmd)
+		}
+		buf.WriteString(cmd)
+		buf.WriteString("\n")
+	}
+
+	ctx, cancel := r.commandContext()
+	defer cancel()
+
+	outputs, generation, err := r.runBatch(ctx, buf.String(), cmds)
+	if err != nil && r.Config.AutoReconnect && errors.Is(err, ErrConnectionLost) {
+		if err = r.reconnect(generation); err != nil {
+			return nil, err
+		}
+		outputs, _, err = r.runBatch(ctx, buf.String(), cmds)
+	}
+
+	return outputs, err
+}
+
+// Write the given commands, one per line, and read the output of each of cmds,
+// and return it along with the generation of the connection they were run on,
+// for reconnect
+func (r *Runner) runBatch(ctx context.Context, lines string, cmds []string) ([]string, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, r.generation, err
+	}
+
+	if err := r.throttle(ctx); err != nil {
+		return nil, r.generation, err
+	}
+	defer r.markCommandDone()
+
+	if err := r.writeCommands(lines); err != nil {
+		return nil, r.generation, err
+	}
+
+	outputs := make([]string, len(cmds))
+	var firstErr error
+
+	for i, cmd := range cmds {
+		output, err := r.readCommandOutput(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				// The output of the commands after this one is still to come
+				r.skip += len(cmds) - i - 1
+				return nil, r.generation, err
+			}
+
+			var cmdErr *CommandError
+			if !errors.As(err, &cmdErr) {
+				return nil, r.generation, fmt.Errorf("Error running command '%s': '%w", shortCommand(cmd), err)
+			}
+
+			cmdErr.Command = cmd
+			if firstErr == nil {
+				firstErr = cmdErr
+			}
+			continue
+		}
+
+		outputs[i] = output
+	}
+
+	return outputs, r.generation, firstErr
+}
@@ -0,0 +1,76 @@
+package tmux
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRunBatchMatchesRun(t *testing.T) {
+	r := newTestRunner(t, Config{})
+
+	cmds := []string{
+		"display-message -p 'first'",
+		"display-message -p '#{session_name}'",
+		"set-option -g @batch-test value",
+		"show-options -gv @batch-test",
+		"display-message -p 'a\tb'",
+	}
+
+	outputs, err := r.RunBatch(cmds)
+	if err != nil {
+		t.Fatalf("RunBatch returned error: %v", err)
+	}
+
+	expected := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		if expected[i], err = r.Run(cmd); err != nil {
+			t.Fatalf("Run(%q) returned error: %v", cmd, err)
+		}
+	}
+
+	if !reflect.DeepEqual(outputs, expected) {
+		t.Errorf("expected the same output as Run, %q, but found %q", expected, outputs)
+	}
+}
+
+func TestRunBatchErrorMatchesRun(t *testing.T) {
+	r := newTestRunner(t, Config{})
+
+	cmds := []string{
+		"display-message -p 'before'",
+		"select-pane -t %99",
+		"no-such-command",
+		"display-message -p 'after'",
+	}
+
+	outputs, batchErr := r.RunBatch(cmds)
+
+	// The error is the first command's to fail, the same as Run returns for it
+	_, runErr := r.Run(cmds[1])
+
+	var batchCmdErr, runCmdErr *CommandError
+	if !errors.As(batchErr, &batchCmdErr) || !errors.As(runErr, &runCmdErr) {
+		t.Fatalf("expected CommandErrors but found %v and %v", batchErr, runErr)
+	}
+	if *batchCmdErr != *runCmdErr {
+		t.Errorf("expected the same error as Run, %+v, but found %+v", runCmdErr, batchCmdErr)
+	}
+
+	if expected := []string{"before", "", "", "after"}; !reflect.DeepEqual(outputs, expected) {
+		t.Errorf("expected outputs %q but found %q", expected, outputs)
+	}
+}
+
+func TestRunBatchCanceled(t *testing.T) {
+	// Nothing is written to tmux, so a Runner which was never started will do
+	r := &Runner{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := r.runBatch(ctx, "list-sessions\n", []string{"list-sessions"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled but found %v", err)
+	}
+}
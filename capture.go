@@ -0,0 +1,141 @@
+package tmux
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Capture the contents of a pane with capture-pane, passing it the given
+// extra flags, like "-S -"
+func (r *Runner) capturePane(pane string, flags string) (string, error) {
+	var cmd string = fmt.Sprintf("capture-pane -p -t %s", Quote(pane))
+	if flags != "" {
+		cmd += " " + flags
+	}
+
+	return r.Run(cmd)
+}
+
+// Returns the text in the visible part of the given pane. tmux reports every
+// row of the pane, including the empty ones below the last output; these
+// trailing blank lines are removed, along with the final newline, so the
+// result ends with the last non-empty row. Colors and other attributes are
+// left out; to keep them, use [Runner.CapturePaneANSI].
+func (r *Runner) CapturePane(target string) (string, error) {
+	output, err := r.capturePane(target, "")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// Like [Runner.CapturePane], but keeps the colors and other attributes of the
+// text, as ANSI escape sequences like "\x1b[31m".
+func (r *Runner) CapturePaneANSI(target string) (string, error) {
+	output, err := r.capturePane(target, "-e")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// Returns a hash of the text in the visible part of the given pane, as
+// returned by [Runner.CapturePane], so that a caller polling the pane can
+// tell whether it has changed without keeping its whole text. The same text
+// always gives the same hash. The text still has to be sent by tmux to be
+// hashed; see [Runner.PaneHasNewOutput] for a check which avoids that.
+func (r *Runner) GetPaneContentHash(target string) (uint64, error) {
+	text, err := r.CapturePane(target)
+	if err != nil {
+		return 0, err
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(text))
+
+	return h.Sum64(), nil
+}
+
+// Returns the text in the given range of rows of the given pane, with
+// trailing blank lines removed as for [Runner.CapturePane]. Row 0 is the top
+// row of the visible part of the pane, and negative rows are in the history
+// above it, so start -100 and end -1 are the 100 rows of history closest to
+// the visible part.
+func (r *Runner) CapturePaneRange(target string, start, end int) (string, error) {
+	output, err := r.capturePane(target, fmt.Sprintf("-S %d -E %d", start, end))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(output, "\n"), nil
+}
+
+// Returns the text in the visible part of each pane in the given window, by
+// pane ID, with trailing blank lines removed as for [Runner.CapturePane]. All
+// of the panes are captured by a single batch of commands, so the captures are
+// close together in time, but they aren't simultaneous: a pane may change
+// between the first capture and the last.
+func (r *Runner) CaptureWindow(windowID string) (map[string]string, error) {
+	var err error
+
+	var output string
+	if output, err = r.Run(fmt.Sprintf("list-panes -t %s -F '#{pane_id}'", Quote(windowID))); err != nil {
+		return nil, err
+	}
+
+	panes := strings.Split(Trim(output), "\n")
+
+	cmds := make([]string, len(panes))
+	for i, pane := range panes {
+		cmds[i] = fmt.Sprintf("capture-pane -p -t %s", Quote(pane))
+	}
+
+	var outputs []string
+	if outputs, err = r.RunBatch(cmds); err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string]string)
+	for i, pane := range panes {
+		contents[pane] = strings.TrimRight(outputs[i], "\n")
+	}
+
+	return contents, nil
+}
+
+// Returns the text added to the given pane since the last call to PaneDelta
+// for that pane. The first call for a pane returns everything in its history
+// and visible area.
+//
+// The runner remembers the whole text of each pane it is called for, and the
+// new text is whatever follows that. If the remembered text no longer comes
+// first, because the pane was cleared or lines dropped off the end of its
+// history, the whole of the current text is returned as new, and becomes the
+// new baseline. Blank lines at the bottom of the pane are ignored, since they
+// are the unused part of the screen rather than output.
+func (r *Runner) PaneDelta(pane string) (string, error) {
+	output, err := r.capturePane(pane, "-J -S -")
+	if err != nil {
+		return "", err
+	}
+
+	text := strings.TrimRight(output, "\n")
+
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+
+	if r.paneContents == nil {
+		r.paneContents = make(map[string]string)
+	}
+	last, seen := r.paneContents[pane]
+	r.paneContents[pane] = text
+
+	if !seen || !strings.HasPrefix(text, last) {
+		return text, nil
+	}
+
+	return text[len(last):], nil
+}
@@ -0,0 +1,158 @@
+package tmux
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A terminal attached to the tmux server, as returned by [Runner.ListClients]
+type Client struct {
+	// The client's name, usually the same as its TTY
+	Name string
+
+	// The name of the session the client is attached to
+	Session string
+
+	// The size of the client's terminal, in cells, or zero if tmux doesn't
+	// know it, as for a control-mode client
+	Width  int
+	Height int
+
+	// The path of the client's terminal, like "/dev/pts/3", or empty for a
+	// control-mode client
+	TTY string
+}
+
+// Returns the clients attached to the tmux server. The runner's own "tmux -C"
+// client is among them. If no clients are attached, the result is empty, not
+// an error.
+func (r *Runner) ListClients() ([]Client, error) {
+	var err error
+
+	var output string
+	var format string = joinFields("#{client_name}", "#{client_session}", "#{client_width}", "#{client_height}", "#{client_tty}")
+	if output, err = r.Run(fmt.Sprintf("list-clients -F %s", Quote(format))); err != nil {
+		return nil, err
+	}
+
+	clients := make([]Client, 0)
+
+	if Trim(output) == "" {
+		return clients, nil
+	}
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		var fields []string
+		if fields, err = splitFields(line, 5); err != nil {
+			return nil, err
+		}
+
+		c := Client{Name: fields[0], Session: fields[1], TTY: fields[4]}
+
+		// Control-mode clients, like the runner's own, may have no size
+		if fields[2] != "" {
+			if c.Width, err = strconv.Atoi(fields[2]); err != nil {
+				return nil, fmt.Errorf("error parsing width of line '%s': '%s'", line, err.Error())
+			}
+		}
+
+		if fields[3] != "" {
+			if c.Height, err = strconv.Atoi(fields[3]); err != nil {
+				return nil, fmt.Errorf("error parsing height of line '%s': '%s'", line, err.Error())
+			}
+		}
+
+		clients = append(clients, c)
+	}
+
+	return clients, nil
+}
+
+// Returns the name of the most recently active client which isn't a
+// control-mode client like the runner's own
+func (r *Runner) mostRecentClient() (string, error) {
+	var cmd string = fmt.Sprintf("list-clients -F %s", Quote(joinFields("#{client_name}", "#{client_activity}", "#{client_control_mode}")))
+	output, err := r.Run(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	var name string
+	var latest int64 = -1
+
+	for _, line := range strings.Split(Trim(output), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var fields []string
+		if fields, err = splitFields(line, 3); err != nil {
+			return "", err
+		}
+
+		if fields[2] == "1" {
+			continue
+		}
+
+		var activity int64
+		if activity, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+			return "", fmt.Errorf("error parsing client activity of line '%s': '%s'", line, err.Error())
+		}
+
+		if activity > latest {
+			name = fields[0]
+			latest = activity
+		}
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("no client other than the runner is attached: %w", ErrNotFound)
+	}
+
+	return name, nil
+}
+
+// Switch the most recently active client to the given session, or window or
+// pane, which may be in another session. Unlike [Runner.AttachSession], this
+// works for a client which is already attached, as a program run from inside
+// tmux is. The runner's own control-mode client is never switched; if there
+// is no other client, returns an error wrapping [ErrNotFound]. Returns the
+// tmux error if the target doesn't exist.
+func (r *Runner) SwitchClient(target string) error {
+	client, err := r.mostRecentClient()
+	if err != nil {
+		return err
+	}
+
+	var cmd string = fmt.Sprintf("switch-client -c %s -t %s", Quote(client), Quote(target))
+
+	_, err = r.Run(cmd)
+	return err
+}
+
+// Show a popup on the most recently active client, the same client
+// [Runner.SwitchClient] would switch, running the given shell command. The
+// popup is the given number of cells wide and high, centred on the client's
+// terminal, and closes when the command exits. This returns as soon as the
+// popup is shown, without waiting for the command. The runner's own
+// control-mode client can't show popups, so if there is no other client,
+// returns an error wrapping [ErrNotFound].
+//
+// Requires tmux 3.2 or later.
+func (r *Runner) DisplayPopup(command string, width, height int) error {
+	if err := r.requireFeature(FeatureDisplayPopup, "display-popup"); err != nil {
+		return err
+	}
+
+	client, err := r.mostRecentClient()
+	if err != nil {
+		return err
+	}
+
+	var cmd string = fmt.Sprintf("display-popup -c %s -w %d -h %d -E %s", Quote(client), width, height, Quote(command))
+
+	_, err = r.Run(cmd)
+	return err
+}
@@ -2,8 +2,8 @@ package tmux
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
-	"strings"
 )
 
 // Tmux doesn't have a built-in notion of a 'column'. A column for the purpose
@@ -35,37 +35,280 @@ type Column struct {
 
 	// The width of this column
 	Width int
+
+	// The height of this column: the height of the pane at the top, plus that
+	// of any panes stacked below it with exactly the same left edge and width,
+	// and the borders between them. In the second example above, the column
+	// for pane 0 is only as tall as pane 0, since pane 2 is wider.
+	Height int
 }
 
-// Returns a list of columns in the active window. See [Column] for details on
-// what a column is.
+// Returns a list of columns in the active window, from left to right. See
+// [Column] for details on what a column is.
 func (r *Runner) ListColumns() ([]Column, error) {
+	positions, err := r.PanePositions()
+	if err != nil {
+		return nil, err
+	}
+
+	return groupColumns(positions), nil
+}
+
+// Group the panes of a window into columns, from left to right, as
+// [Runner.ListColumns] does
+func groupColumns(positions []PanePosition) []Column {
+	sorted := make([]PanePosition, len(positions))
+	copy(sorted, positions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Left < sorted[j].Left
+	})
+
+	columns := make([]Column, 0)
+	if len(sorted) == 0 {
+		return columns
+	}
+
+	// The panes at the top of the window aren't always on row 0: with
+	// pane-border-status set to "top", they start below their border
+	firstRow := sorted[0].Top
+	for _, p := range sorted {
+		if p.Top < firstRow {
+			firstRow = p.Top
+		}
+	}
+
+	for _, top := range sorted {
+		if top.Top != firstRow {
+			continue
+		}
+
+		// Each pane in the stack starts on the row after the border below the
+		// one above it
+		bottom := top.Top + top.Height
+		for extended := true; extended; {
+			extended = false
+			for _, p := range sorted {
+				if p.Left == top.Left && p.Width == top.Width && p.Top == bottom+1 {
+					bottom = p.Top + p.Height
+					extended = true
+					break
+				}
+			}
+		}
+
+		columns = append(columns, Column{Pane: top.Pane, Width: top.Width, Height: bottom - firstRow})
+	}
+
+	return columns
+}
+
+// Resize the columns of the active window so they share its width evenly. When
+// the width doesn't divide evenly, the leftmost columns get one extra cell
+// each, so that the columns and the borders between them still fill the
+// window. The columns are resized all at once; see [Runner.SetColumnWidths].
+func (r *Runner) BalanceColumns() error {
+	columns, err := r.ListColumns()
+	if err != nil {
+		return err
+	}
+
+	if len(columns) < 2 {
+		return nil
+	}
+
+	return r.SetColumnWidths(balancedWidths(columns))
+}
+
+// Returns the width of each column, by the ID of the pane at its top, to share
+// the width the columns take up now evenly between them, as
+// [Runner.BalanceColumns] does
+func balancedWidths(columns []Column) map[string]int {
+	// The columns are separated by a one-cell border, which stays as it is
+	available := 0
+	for _, c := range columns {
+		available += c.Width
+	}
+
+	widths := make(map[string]int)
+	if len(columns) == 0 {
+		return widths
+	}
+
+	width := available / len(columns)
+	extra := available % len(columns)
+
+	for i, c := range columns {
+		widths[c.Pane] = width
+		if i < extra {
+			widths[c.Pane]++
+		}
+	}
+
+	return widths
+}
+
+// Resize the columns of the active window, given the width of each by the ID
+// of the pane at its top, as in [Column]. Every column must be given a width,
+// and the widths, plus a one-cell border between each pair of columns, must
+// add up to the width of the window; otherwise an error is returned and
+// nothing is changed.
+//
+// Resizing the columns one at a time reflows the layout after each, which
+// flickers and can briefly squeeze the other columns. Where the columns are
+// the top-level split of the window's layout, as they are unless panes have
+// been split across columns, a new layout is worked out with every column at
+// its new width and applied with a single select-layout. Panes stacked in a
+// column take its width, and panes side by side within a column share it in
+// proportion to their old widths. Otherwise, the columns are resized from left
+// to right by a single batch of resize-pane commands; a pane spanning several
+// columns may make some widths impossible to reach that way, in which case an
+// error is returned after resizing as closely as tmux allows.
+func (r *Runner) SetColumnWidths(widths map[string]int) error {
 	var err error
 
+	var columns []Column
+	if columns, err = r.ListColumns(); err != nil {
+		return err
+	}
+
+	if len(columns) == 0 {
+		return nil
+	}
+
+	total := len(columns) - 1
+	for _, c := range columns {
+		w, ok := widths[c.Pane]
+		if !ok {
+			return fmt.Errorf("no width given for the column at pane '%s'", c.Pane)
+		}
+		if w < 1 {
+			return fmt.Errorf("expected width of the column at pane '%s' to be at least 1 but found %d", c.Pane, w)
+		}
+		total += w
+	}
+	if len(widths) != len(columns) {
+		return fmt.Errorf("expected widths for %d columns but found %d", len(columns), len(widths))
+	}
+
 	var output string
-	var cmd string = "list-panes -F '#{pane_id} #{pane_width}' -f '#{m:#{pane_at_top},1}'"
-	if output, err = r.Run(cmd); err != nil {
+	if output, err = r.displayMessage(columns[0].Pane, joinFields("#{window_width}", "#{window_layout}")); err != nil {
+		return err
+	}
+
+	var fields []string
+	if fields, err = splitFields(output, 2); err != nil {
+		return err
+	}
+
+	if fields[0] != strconv.Itoa(total) {
+		return fmt.Errorf("expected column widths and borders to add up to the window width of %s but found %d", fields[0], total)
+	}
+
+	var root *layoutCell
+	if root, err = parseLayout(fields[1]); err != nil {
+		return err
+	}
+
+	if root.split == '{' && len(root.children) == len(columns) {
+		matched := true
+		for _, child := range root.children {
+			if _, ok := widths[child.firstPane()]; !ok {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			for _, child := range root.children {
+				child.setWidth(widths[child.firstPane()])
+			}
+			root.setX(root.x)
+
+			var cmd string = fmt.Sprintf("select-layout -t %s %s", Quote(columns[0].Pane), Quote(root.layout()))
+
+			_, err = r.Run(cmd)
+			return err
+		}
+	}
+
+	// Resizing a column moves the border on its right, so working left to
+	// right leaves the last column with whatever remains
+	cmds := make([]string, 0, len(columns)-1)
+	for _, c := range columns[:len(columns)-1] {
+		cmds = append(cmds, fmt.Sprintf("resize-pane -x %d -t %s", widths[c.Pane], Quote(c.Pane)))
+	}
+
+	if _, err = r.RunBatch(cmds); err != nil {
+		return err
+	}
+
+	// A pane below several columns ties their widths together, so resizing
+	// them one by one may not be able to give each the width asked for
+	if columns, err = r.ListColumns(); err != nil {
+		return err
+	}
+	for _, c := range columns {
+		if w, ok := widths[c.Pane]; ok && c.Width != w {
+			return fmt.Errorf("could only make the column at pane '%s' %d wide rather than %d, because of the panes which span columns", c.Pane, c.Width, w)
+		}
+	}
+
+	return nil
+}
+
+// A group of panes which share a left edge, as returned by
+// [Runner.ListColumnsDetailed]
+type ColumnDetail struct {
+	// The IDs of the panes in this column, from top to bottom
+	Panes []string
+
+	// The x-offset of the column's left edge in the window
+	X int
+
+	// The width of the widest pane in this column
+	Width int
+}
+
+// Returns the columns of the active window, from left to right, with every
+// pane in each. Unlike [Runner.ListColumns], this puts panes in the same
+// column if and only if they have the same left edge, whatever their widths.
+// In the second example in the documentation of [Column], this gives three
+// columns: panes 0 and 2, whose left edges are both at the left of the window,
+// with the width of pane 2; pane 1; and pane 3.
+func (r *Runner) ListColumnsDetailed() ([]ColumnDetail, error) {
+	positions, err := r.PanePositions()
+	if err != nil {
 		return nil, err
 	}
 
-	columns := make([]Column, 0)
+	return groupColumnsDetailed(positions), nil
+}
 
-	lines := strings.Split(Trim(output), "\n")
-	for _, line := range lines {
-		tokens := strings.Split(line, " ")
-		if len(tokens) != 2 {
-			return nil, fmt.Errorf("expected line to be a string with two elements separated by spaces but found '%s'", line)
+// Group the panes of a window by their left edges, from left to right, as
+// [Runner.ListColumnsDetailed] does
+func groupColumnsDetailed(positions []PanePosition) []ColumnDetail {
+	sorted := make([]PanePosition, len(positions))
+	copy(sorted, positions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Left != sorted[j].Left {
+			return sorted[i].Left < sorted[j].Left
 		}
+		return sorted[i].Top < sorted[j].Top
+	})
 
-		pane := tokens[0]
+	columns := make([]ColumnDetail, 0)
 
-		var width int
-		if width, err = strconv.Atoi(tokens[1]); err != nil {
-			return nil, fmt.Errorf("error parsing second element of line '%s': '%s'", line, err.Error())
+	for _, p := range sorted {
+		if len(columns) == 0 || columns[len(columns)-1].X != p.Left {
+			columns = append(columns, ColumnDetail{X: p.Left})
 		}
 
-		columns = append(columns, Column{Pane: pane, Width: width})
+		c := &columns[len(columns)-1]
+		c.Panes = append(c.Panes, p.Pane)
+		if p.Width > c.Width {
+			c.Width = p.Width
+		}
 	}
 
-	return columns, nil
+	return columns
 }
@@ -0,0 +1,166 @@
+package tmux
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupColumns(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions []PanePosition
+		expected  []Column
+	}{
+		{
+			"one pane",
+			[]PanePosition{{"%0", 0, 0, 80, 24}},
+			[]Column{{"%0", 80, 24}},
+		},
+		{
+			"stacked column",
+			[]PanePosition{
+				{"%0", 0, 0, 40, 24},
+				{"%2", 41, 13, 39, 11},
+				{"%1", 41, 0, 39, 12},
+			},
+			[]Column{{"%0", 40, 24}, {"%1", 39, 24}},
+		},
+		{
+			// With pane-border-status set to "top", every pane starts a row
+			// lower than it otherwise would
+			"border status at top",
+			[]PanePosition{
+				{"%0", 0, 1, 40, 23},
+				{"%1", 41, 1, 39, 11},
+				{"%2", 41, 13, 39, 11},
+			},
+			[]Column{{"%0", 40, 23}, {"%1", 39, 23}},
+		},
+		{
+			"border status at bottom",
+			[]PanePosition{
+				{"%0", 0, 0, 40, 23},
+				{"%1", 41, 0, 39, 12},
+				{"%2", 41, 13, 39, 10},
+			},
+			[]Column{{"%0", 40, 23}, {"%1", 39, 23}},
+		},
+		{
+			// The second example in the documentation of Column: pane 2 is
+			// wider than pane 0, so it isn't part of pane 0's column
+			"pane spanning columns",
+			[]PanePosition{
+				{"%0", 0, 0, 20, 12},
+				{"%1", 21, 0, 19, 12},
+				{"%2", 0, 13, 40, 11},
+				{"%3", 41, 0, 39, 24},
+			},
+			[]Column{{"%0", 20, 12}, {"%1", 19, 12}, {"%3", 39, 24}},
+		},
+		{
+			"no panes",
+			[]PanePosition{},
+			[]Column{},
+		},
+	}
+
+	for _, test := range tests {
+		if actual := groupColumns(test.positions); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %+v but found %+v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestGroupColumnsDetailed(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions []PanePosition
+		expected  []ColumnDetail
+	}{
+		{
+			"one pane",
+			[]PanePosition{{"%0", 0, 0, 80, 24}},
+			[]ColumnDetail{{[]string{"%0"}, 0, 80}},
+		},
+		{
+			"stacked column, out of order",
+			[]PanePosition{
+				{"%2", 41, 13, 39, 11},
+				{"%0", 0, 0, 40, 24},
+				{"%1", 41, 0, 39, 12},
+			},
+			[]ColumnDetail{{[]string{"%0"}, 0, 40}, {[]string{"%1", "%2"}, 41, 39}},
+		},
+		{
+			// The second example in the documentation of Column: panes 0 and
+			// 2 share a left edge, so they are one column as wide as pane 2
+			"pane spanning columns",
+			[]PanePosition{
+				{"%0", 0, 0, 20, 12},
+				{"%1", 21, 0, 19, 12},
+				{"%2", 0, 13, 40, 11},
+				{"%3", 41, 0, 39, 24},
+			},
+			[]ColumnDetail{{[]string{"%0", "%2"}, 0, 40}, {[]string{"%1"}, 21, 19}, {[]string{"%3"}, 41, 39}},
+		},
+		{
+			"no panes",
+			[]PanePosition{},
+			[]ColumnDetail{},
+		},
+	}
+
+	for _, test := range tests {
+		if actual := groupColumnsDetailed(test.positions); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %+v but found %+v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestBalancedWidths(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  []Column
+		expected map[string]int
+	}{
+		{
+			"two columns",
+			[]Column{{"%0", 60, 24}, {"%1", 19, 24}},
+			map[string]int{"%0": 40, "%1": 39},
+		},
+		{
+			// 77 cells, in a window 79 wide with its 2 borders, leave 2 over
+			// after 25 each, which go to the leftmost columns
+			"three columns with a remainder",
+			[]Column{{"%0", 10, 24}, {"%1", 50, 24}, {"%2", 17, 24}},
+			map[string]int{"%0": 26, "%1": 26, "%2": 25},
+		},
+		{
+			"three columns already even",
+			[]Column{{"%0", 26, 24}, {"%1", 26, 24}, {"%2", 26, 24}},
+			map[string]int{"%0": 26, "%1": 26, "%2": 26},
+		},
+		{
+			"no columns",
+			[]Column{},
+			map[string]int{},
+		},
+	}
+
+	for _, test := range tests {
+		actual := balancedWidths(test.columns)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %v but found %v", test.name, test.expected, actual)
+		}
+
+		// The columns take up the same width as before, borders aside
+		before, after := 0, 0
+		for _, c := range test.columns {
+			before += c.Width
+			after += actual[c.Pane]
+		}
+		if before != after {
+			t.Errorf("%s: expected the widths to add up to %d but found %d", test.name, before, after)
+		}
+	}
+}
@@ -1,18 +1,137 @@
 package tmux
 
-import "os/exec"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Returns the arguments which go before the tmux command for the given config:
+// "-f" with its ConfigFile, then "-L" with its Socket, for those which are set.
+// Returns an error if ConfigFile is set but doesn't exist.
+func globalArgs(c Config) ([]string, error) {
+	args := make([]string, 0, 4)
+
+	if c.ConfigFile != "" {
+		if _, err := os.Stat(c.ConfigFile); err != nil {
+			return nil, fmt.Errorf("error checking tmux config file '%s': '%s'", c.ConfigFile, err.Error())
+		}
+		args = append(args, "-f", c.ConfigFile)
+	}
+
+	if c.Socket != "" {
+		args = append(args, "-L", c.Socket)
+	}
+
+	return args, nil
+}
+
+// Returns an exec.Cmd to run tmux with the given arguments, after the
+// arguments from the config, which is killed if ctx is done before it exits
+func tmuxExecCommand(ctx context.Context, c Config, args ...string) (*exec.Cmd, error) {
+	tmuxPath, err := tmuxBinary(c)
+	if err != nil {
+		return nil, err
+	}
+
+	global, err := globalArgs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return exec.CommandContext(ctx, tmuxPath, append(global, args...)...), nil
+}
+
+// Returns true if stderr is tmux reporting that no server is running. If the
+// socket doesn't exist at all, or the server which made it is gone, tmux
+// reports that it can't connect rather than that there's no server.
+func isNoServer(stderr string) bool {
+	if strings.HasPrefix(stderr, "no server running on ") {
+		return true
+	}
+
+	return strings.HasPrefix(stderr, "error connecting to ") &&
+		(strings.Contains(stderr, "(No such file or directory)") || strings.Contains(stderr, "(Connection refused)"))
+}
+
+// Returns the error for tmux, run with the given arguments, failing with err
+// after writing stderr: an error wrapping ErrNoServer if stderr says no server
+// is running, a *CommandError if tmux exited with an error, otherwise err
+func commandError(err error, args []string, stderr []byte) error {
+	if isNoServer(string(stderr)) {
+		return fmt.Errorf("%w: %s", ErrNoServer, strings.TrimSpace(string(stderr)))
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+
+	return &CommandError{
+		Command:  strings.Join(quoted, " "),
+		Message:  strings.TrimSpace(string(stderr)),
+		ExitCode: exitErr.ExitCode(),
+		Err:      err,
+	}
+}
 
 // Run a tmux shell command with the provided arguments, and return its output.
+// If the command needs a server and none is running, returns an error wrapping
+// [ErrNoServer]; if tmux exits with an error otherwise, returns a
+// [CommandError] with its message and exit status.
 func Command(c Config, args ...string) ([]byte, error) {
-	var tmuxPath string
-	var err error
+	return CommandContext(context.Background(), c, args...)
+}
 
-	if tmuxPath, err = Tmux(); err != nil {
+// Like [Command], but kills tmux if ctx is done before it exits, and then
+// returns ctx.Err()
+func CommandContext(ctx context.Context, c Config, args ...string) ([]byte, error) {
+	cmd, err := tmuxExecCommand(ctx, c, args...)
+	if err != nil {
 		return []byte(""), err
 	}
 
-	if c.Socket != "" {
-		args = append([]string{"-L", c.Socket}, args...)
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return output, ctx.Err()
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return output, commandError(err, args, exitErr.Stderr)
+		}
+	}
+
+	return output, err
+}
+
+// Like [Command], but also returns what tmux wrote to stderr, such as its
+// error message, like "can't find session: foo", if the command failed. err
+// is a [CommandError] if tmux ran but exited with an error, or wraps
+// [ErrNoServer] if no server is running.
+func CommandWithStderr(c Config, args ...string) (stdout []byte, stderr []byte, err error) {
+	cmd, err := tmuxExecCommand(context.Background(), c, args...)
+	if err != nil {
+		return nil, nil, err
 	}
-	return exec.Command(tmuxPath, args...).Output()
+
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	if err = cmd.Run(); err != nil {
+		err = commandError(err, args, errBuf.Bytes())
+	}
+
+	return outBuf.Bytes(), errBuf.Bytes(), err
 }
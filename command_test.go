@@ -0,0 +1,153 @@
+package tmux
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsNoServer(t *testing.T) {
+	tests := []struct {
+		stderr   string
+		noServer bool
+	}{
+		{"no server running on /tmp/tmux-1000/default\n", true},
+		{"error connecting to /tmp/tmux-1000/default (No such file or directory)\n", true},
+		{"error connecting to /tmp/tmux-1000/default (Connection refused)\n", true},
+		{"error connecting to /tmp/tmux-1000/default (Permission denied)\n", false},
+		{"can't find session: foo\n", false},
+		{"server exited unexpectedly\n", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if actual := isNoServer(test.stderr); actual != test.noServer {
+			t.Errorf("isNoServer(%q): expected %v but found %v", test.stderr, test.noServer, actual)
+		}
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	exitErr := &exec.ExitError{}
+
+	tests := []struct {
+		stderr       string
+		noServer     bool
+		commandError bool
+		notFound     bool
+	}{
+		{"no server running on /tmp/tmux-1000/default\n", true, false, false},
+		{"error connecting to /tmp/tmux-1000/default (Connection refused)\n", true, false, false},
+		{"error connecting to /tmp/tmux-1000/default (Permission denied)\n", false, true, false},
+		{"can't find session: foo\n", false, true, true},
+		{"unknown command: foo\n", false, true, false},
+	}
+
+	for _, test := range tests {
+		err := commandError(exitErr, []string{"has-session", "-t", "foo"}, []byte(test.stderr))
+
+		var cmdErr *CommandError
+		if actual := errors.As(err, &cmdErr); actual != test.commandError {
+			t.Errorf("%q: expected a CommandError %v but found %v", test.stderr, test.commandError, err)
+		}
+		if actual := errors.Is(err, ErrNoServer); actual != test.noServer {
+			t.Errorf("%q: expected ErrNoServer %v but found %v", test.stderr, test.noServer, err)
+		}
+		if actual := errors.Is(err, ErrNotFound); actual != test.notFound {
+			t.Errorf("%q: expected ErrNotFound %v but found %v", test.stderr, test.notFound, err)
+		}
+	}
+}
+
+func TestCommandErrorNotExitError(t *testing.T) {
+	// tmux didn't run at all, so there's nothing for a CommandError to say
+	if err := commandError(exec.ErrNotFound, []string{"list-sessions"}, nil); err != exec.ErrNotFound {
+		t.Errorf("expected the error to be returned unchanged but found %v", err)
+	}
+}
+
+// Write a file for a test, failing the test if it can't be written
+func writeFile(t *testing.T, name, content string, perm os.FileMode) {
+	t.Helper()
+
+	if err := os.WriteFile(name, []byte(content), perm); err != nil {
+		t.Fatalf("error writing %s: %v", name, err)
+	}
+}
+
+func TestGlobalArgs(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "tmux.conf")
+	writeFile(t, configFile, "", 0644)
+
+	tests := []struct {
+		config   Config
+		expected []string
+	}{
+		{Config{}, []string{}},
+		{Config{Socket: "work"}, []string{"-L", "work"}},
+		{Config{ConfigFile: configFile}, []string{"-f", configFile}},
+		{Config{Socket: "work", ConfigFile: configFile}, []string{"-f", configFile, "-L", "work"}},
+	}
+
+	for _, test := range tests {
+		args, err := globalArgs(test.config)
+		if err != nil {
+			t.Errorf("globalArgs(%+v) returned error: %v", test.config, err)
+			continue
+		}
+		if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("globalArgs(%+v): expected %q but found %q", test.config, test.expected, args)
+		}
+	}
+}
+
+func TestGlobalArgsMissingConfigFile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "missing.conf")
+	if _, err := globalArgs(Config{ConfigFile: configFile}); err == nil || !strings.Contains(err.Error(), configFile) {
+		t.Errorf("expected an error naming the missing config file but found %v", err)
+	}
+}
+
+func TestControlArgs(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "tmux.conf")
+	writeFile(t, configFile, "", 0644)
+
+	// tmux only takes its own flags before the command, so -f and -L have to
+	// come before -C
+	tests := []struct {
+		config      Config
+		sessionName string
+		expected    []string
+	}{
+		{
+			Config{},
+			"tmux-runner-1",
+			[]string{"-C", "new-session", "-s", "tmux-runner-1"},
+		},
+		{
+			Config{Socket: "work", ConfigFile: configFile},
+			"tmux-runner-1",
+			[]string{"-f", configFile, "-L", "work", "-C", "new-session", "-s", "tmux-runner-1"},
+		},
+		{
+			Config{Socket: "work", ExistingSession: "main"},
+			"main",
+			[]string{"-L", "work", "-C", "attach-session", "-t", "=main"},
+		},
+	}
+
+	for _, test := range tests {
+		args, err := controlArgs(test.config, test.sessionName)
+		if err != nil {
+			t.Errorf("controlArgs(%+v) returned error: %v", test.config, err)
+			continue
+		}
+		if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("controlArgs(%+v): expected %q but found %q", test.config, test.expected, args)
+		}
+	}
+}
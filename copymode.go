@@ -0,0 +1,221 @@
+package tmux
+
+import "fmt"
+
+// Returns true if the given pane is in a mode, like copy mode
+func (r *Runner) paneInMode(pane string) (bool, error) {
+	output, err := r.displayMessage(pane, "#{pane_in_mode}")
+	if err != nil {
+		return false, err
+	}
+
+	return output == "1", nil
+}
+
+// Run a copy-mode command, like "cursor-down", in the given pane, repeated
+// count times. Does nothing if count is 0, and returns an error if it is
+// negative, since tmux rejects a repeat count below 1.
+func (r *Runner) copyModeCommand(pane string, command string, count int) error {
+	if count == 0 {
+		return nil
+	}
+	if count < 0 {
+		return fmt.Errorf("expected a count of at least 0 for copy-mode command '%s' but found %d", command, count)
+	}
+
+	var cmd string = fmt.Sprintf("send-keys -X -t %s", Quote(pane))
+	if count != 1 {
+		cmd += fmt.Sprintf(" -N %d", count)
+	}
+	cmd += fmt.Sprintf(" %s", Quote(command))
+
+	_, err := r.Run(cmd)
+	return err
+}
+
+// Move the copy-mode cursor in the given pane to column x of row y of the
+// visible part of the pane
+func (r *Runner) copyModeMoveTo(pane string, x, y int) error {
+	var err error
+
+	if err = r.copyModeCommand(pane, "top-line", 1); err != nil {
+		return err
+	}
+	if err = r.copyModeCommand(pane, "start-of-line", 1); err != nil {
+		return err
+	}
+	if y > 0 {
+		if err = r.copyModeCommand(pane, "cursor-down", y); err != nil {
+			return err
+		}
+	}
+	if x > 0 {
+		if err = r.copyModeCommand(pane, "cursor-right", x); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Put the given pane in copy mode, the same as pressing the prefix key and
+// [, so that it can be scrolled back with [Runner.ScrollUp]. While the pane is
+// in copy mode, keys typed in it are handled by copy mode rather than reaching
+// the program running in it, so anyone using the pane interactively will
+// notice; use [Runner.EnsureNormalMode] to leave it. If the pane is already in
+// copy mode, nothing is changed.
+func (r *Runner) EnterCopyMode(target string) error {
+	_, err := r.Run(fmt.Sprintf("copy-mode -t %s", Quote(target)))
+	return err
+}
+
+// Scroll the given pane, which must be in copy mode, up by the given number
+// of lines, into its history. Returns the tmux error if the pane isn't in
+// copy mode; see [Runner.EnterCopyMode]. Scrolling by 0 lines does nothing,
+// and a negative number of lines is an error.
+func (r *Runner) ScrollUp(target string, lines int) error {
+	return r.copyModeCommand(target, "scroll-up", lines)
+}
+
+// Scroll the given pane, which must be in copy mode, down by the given number
+// of lines, back towards the visible part of the pane. Returns the tmux error
+// if the pane isn't in copy mode; see [Runner.EnterCopyMode]. Scrolling by 0
+// lines does nothing, and a negative number of lines is an error.
+func (r *Runner) ScrollDown(target string, lines int) error {
+	return r.copyModeCommand(target, "scroll-down", lines)
+}
+
+// Select the text in the given pane from column startX of row startY to
+// column endX of row endY, inclusive, and return it. Rows and columns count
+// from 0 at the top left of the visible part of the pane; if the pane is
+// already in copy mode and scrolled back, they are relative to what is shown.
+//
+// This works the way a user would: it enters copy mode, moves the cursor with
+// the copy-mode commands "top-line", "start-of-line", "cursor-down", and
+// "cursor-right", which don't depend on the vi or emacs key table, then
+// selects with "begin-selection" and copies with "copy-selection". The
+// selection includes the cell under the cursor with vi keys but not with emacs
+// keys, so the end is adjusted according to the pane's mode-keys option. The
+// text is read from the paste buffer this creates, which is then deleted. If
+// the pane wasn't in copy mode to begin with, copy mode is cancelled
+// afterwards.
+func (r *Runner) CopySelection(pane string, startX, startY, endX, endY int) (text string, err error) {
+	var wasInMode bool
+	if wasInMode, err = r.paneInMode(pane); err != nil {
+		return "", err
+	}
+
+	if !wasInMode {
+		if err = r.EnterCopyMode(pane); err != nil {
+			return "", err
+		}
+
+		defer func() {
+			// Older versions of tmux leave copy mode after copy-selection
+			inMode, e := r.paneInMode(pane)
+			if e == nil && inMode {
+				e = r.copyModeCommand(pane, "cancel", 1)
+			}
+			if e != nil && err == nil {
+				err = e
+			}
+		}()
+	}
+
+	if err = r.copyModeMoveTo(pane, startX, startY); err != nil {
+		return "", err
+	}
+	if err = r.copyModeCommand(pane, "begin-selection", 1); err != nil {
+		return "", err
+	}
+	// With emacs keys, the selection stops before the cursor, and with vi keys
+	// it includes it
+	var modeKeys string
+	if modeKeys, err = r.displayMessage(pane, "#{mode-keys}"); err != nil {
+		return "", err
+	}
+	if modeKeys != "vi" {
+		endX++
+	}
+
+	if err = r.copyModeMoveTo(pane, endX, endY); err != nil {
+		return "", err
+	}
+	if err = r.copyModeCommand(pane, "copy-selection", 1); err != nil {
+		return "", err
+	}
+
+	var output string
+	if output, err = r.Run("show-buffer"); err != nil {
+		return "", err
+	}
+	if _, err = r.Run("delete-buffer"); err != nil {
+		return "", err
+	}
+
+	return output, nil
+}
+
+// Make sure the given pane isn't in copy mode, or any other mode, so that keys
+// sent to it reach the program running in it rather than being handled by the
+// mode. If the pane is in a mode, it is left with the "cancel" command, the
+// same as pressing q in copy mode; if it isn't, nothing is done.
+func (r *Runner) EnsureNormalMode(pane string) error {
+	inMode, err := r.paneInMode(pane)
+	if err != nil {
+		return err
+	}
+
+	if !inMode {
+		return nil
+	}
+
+	return r.copyModeCommand(pane, "cancel", 1)
+}
+
+// Returns the text selected in the given pane, or an empty string if the pane
+// isn't in copy mode or nothing is selected.
+//
+// tmux doesn't make the selected text available as a format, so it is copied
+// with the copy-mode command "copy-selection-no-clear", which leaves the
+// selection as it is, and read from the paste buffer this creates, which is
+// then deleted. Like any copy, this also sets the terminal's clipboard if the
+// set-clipboard option is on.
+func (r *Runner) CurrentSelection(pane string) (string, error) {
+	var err error
+
+	var inMode bool
+	if inMode, err = r.paneInMode(pane); err != nil || !inMode {
+		return "", err
+	}
+
+	var present string
+	if present, err = r.displayMessage(pane, "#{selection_present}"); err != nil || present != "1" {
+		return "", err
+	}
+
+	if err = r.copyModeCommand(pane, "copy-selection-no-clear", 1); err != nil {
+		return "", err
+	}
+
+	var output string
+	if output, err = r.Run("show-buffer"); err != nil {
+		return "", err
+	}
+	if _, err = r.Run("delete-buffer"); err != nil {
+		return "", err
+	}
+
+	return output, nil
+}
+
+// Get the global word-separators option: the characters which, along with
+// spaces, separate words for word-wise movement and selection in copy mode
+func (r *Runner) GetWordSeparators() (string, error) {
+	return r.GetOption(OptionScope{Global: true}, "word-separators")
+}
+
+// Set the global word-separators option. See [Runner.GetWordSeparators].
+func (r *Runner) SetWordSeparators(separators string) error {
+	return r.SetOption(OptionScope{Global: true}, "word-separators", separators)
+}
@@ -0,0 +1,30 @@
+package tmux
+
+import "fmt"
+
+// Expand a format, like "#{pane_current_path}", using display-message. The
+// format is expanded for target, which may be a session, window, or pane, or
+// for the runner's current pane if target is empty.
+func (r *Runner) displayMessage(target, format string) (string, error) {
+	var cmd string = "display-message -p"
+	if target != "" {
+		cmd += fmt.Sprintf(" -t %s", Quote(target))
+	}
+	cmd += fmt.Sprintf(" %s", Quote(format))
+
+	output, err := r.Run(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return Trim(output), nil
+}
+
+// Expand a format, like "#{pane_current_path}" or
+// "#{client_width}x#{client_height}", for the runner's current pane, and
+// return the result with any trailing newline removed. This gives access to
+// any of the values tmux makes available as formats; see the FORMATS section
+// of the tmux manual.
+func (r *Runner) DisplayMessage(format string) (string, error) {
+	return r.displayMessage("", format)
+}
@@ -0,0 +1,61 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Returned when a lookup, such as finding the pane at a given coordinate,
+// matches nothing
+var ErrNotFound = errors.New("not found")
+
+// Returned by [Command] when tmux reports that no server is running on the
+// socket, which is normal before the first session is started, rather than
+// that the command failed. [Runner.Init] doesn't need a server to be running;
+// it starts one if there isn't.
+var ErrNoServer = errors.New("no tmux server running")
+
+// Returned by [Runner.Run] and the methods built on it when the "tmux -C"
+// process has gone away, for instance because the tmux server was restarted.
+// Use [Runner.Reconnect] to start a new one, or set [Config.AutoReconnect].
+var ErrConnectionLost = errors.New("connection to tmux lost")
+
+// Returned by [Runner.Run] and the methods built on it, and by [Command] and
+// the functions like it, when tmux reports that a command failed. Use
+// errors.Is(err, ErrNotFound) to tell whether it failed because its target,
+// like a session or pane, doesn't exist.
+type CommandError struct {
+	// The command which failed
+	Command string
+
+	// The error message from tmux, like "can't find session: foo"
+	Message string
+
+	// The status tmux exited with, for [Command] and the functions like it;
+	// always 0 for a [Runner], whose tmux process keeps running
+	ExitCode int
+
+	// The *exec.ExitError, for [Command] and the functions like it; nil for a
+	// [Runner]
+	Err error
+}
+
+func (e *CommandError) Error() string {
+	if e.Command == "" {
+		return fmt.Sprintf("tmux error: %s", e.Message)
+	}
+
+	return fmt.Sprintf("Error running command '%s': 'tmux error: %s", e.Command, e.Message)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Reports whether the command failed because its target doesn't exist, so
+// that errors.Is(err, ErrNotFound) is true for messages like
+// "can't find session: foo"
+func (e *CommandError) Is(target error) bool {
+	return target == ErrNotFound && strings.HasPrefix(e.Message, "can't find ")
+}
@@ -0,0 +1,108 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestCommandErrorIsNotFound(t *testing.T) {
+	tests := []struct {
+		message  string
+		notFound bool
+	}{
+		{"can't find session: foo", true},
+		{"can't find window: 9", true},
+		{"can't find pane: %99", true},
+		{"can't find client: /dev/pts/9", true},
+		{"duplicate session: foo", false},
+		{"unknown command: foo", false},
+		{"invalid option: @foo", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := &CommandError{Command: "kill-session -t foo", Message: test.message}
+		if actual := errors.Is(err, ErrNotFound); actual != test.notFound {
+			t.Errorf("errors.Is(%q, ErrNotFound): expected %v but found %v", test.message, test.notFound, actual)
+		}
+		if errors.Is(err, ErrNoServer) {
+			t.Errorf("errors.Is(%q, ErrNoServer): expected false", test.message)
+		}
+	}
+}
+
+func TestCommandErrorAs(t *testing.T) {
+	exitErr := &exec.ExitError{}
+	var err error = fmt.Errorf("error setting up: %w", &CommandError{
+		Command:  "has-session -t foo",
+		Message:  "can't find session: foo",
+		ExitCode: 1,
+		Err:      exitErr,
+	})
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("expected errors.As to find the CommandError in %v", err)
+	}
+	if cmdErr.Command != "has-session -t foo" || cmdErr.ExitCode != 1 {
+		t.Errorf("expected the CommandError's fields to be kept but found %+v", cmdErr)
+	}
+
+	var unwrapped *exec.ExitError
+	if !errors.As(err, &unwrapped) || unwrapped != exitErr {
+		t.Errorf("expected errors.As to find the *exec.ExitError in %v", err)
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected errors.Is to find ErrNotFound in %v", err)
+	}
+}
+
+func TestCommandErrorMessage(t *testing.T) {
+	tests := []struct {
+		err      *CommandError
+		expected string
+	}{
+		{&CommandError{Message: "unknown command: foo"}, "tmux error: unknown command: foo"},
+		{&CommandError{Command: "foo", Message: "unknown command: foo"}, "Error running command 'foo': 'tmux error: unknown command: foo"},
+	}
+
+	for _, test := range tests {
+		if actual := test.err.Error(); actual != test.expected {
+			t.Errorf("expected %q but found %q", test.expected, actual)
+		}
+	}
+}
+
+// The errors from real tmux, both through Command and through a Runner
+func TestTmuxErrors(t *testing.T) {
+	r := newTestRunner(t, Config{})
+
+	_, err := r.Run("select-pane -t %99")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a CommandError matching ErrNotFound but found %v", err)
+	}
+
+	_, err = r.Run("no-such-command")
+	if !errors.As(err, &cmdErr) || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a CommandError not matching ErrNotFound but found %v", err)
+	}
+
+	_, err = Command(r.Config, "has-session", "-t", "=no-such-session")
+	if !errors.As(err, &cmdErr) || !errors.Is(err, ErrNotFound) || cmdErr.ExitCode != 1 {
+		t.Errorf("expected a CommandError with exit code 1 matching ErrNotFound but found %v", err)
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Errorf("expected the CommandError to wrap an *exec.ExitError but found %v", err)
+	}
+
+	_, err = Command(Config{Socket: r.Config.Socket + "-none"}, "has-session", "-t", "foo")
+	if !errors.Is(err, ErrNoServer) || errors.As(err, &cmdErr) {
+		t.Errorf("expected an error matching ErrNoServer but found %v", err)
+	}
+}
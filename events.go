@@ -0,0 +1,143 @@
+package tmux
+
+// The kind of an [Event], as passed to [Runner.Subscribe]
+type EventType string
+
+const (
+	// Output written by the program in a pane; see [PaneOutputEvent]
+	EventPaneOutput EventType = "pane-output"
+
+	// A window was created; see [WindowAddEvent]
+	EventWindowAdd EventType = "window-add"
+
+	// A session was renamed; see [SessionRenamedEvent]
+	EventSessionRenamed EventType = "session-renamed"
+
+	// The panes of a window were rearranged or resized; see
+	// [LayoutChangeEvent]
+	EventLayoutChange EventType = "layout-change"
+
+	// The tmux process stopped sending events; see [ExitEvent]
+	EventExit EventType = "exit"
+)
+
+// A change reported by tmux, as received from [Runner.Subscribe]. Use a type
+// switch to get at the details of each kind of event.
+type Event interface {
+	Type() EventType
+}
+
+// Output written by the program in a pane, as raw bytes, escape sequences and
+// all. tmux only reports the output of panes in windows in the runner's own
+// session; see [Runner.SubscribeOutput] to follow other panes.
+type PaneOutputEvent struct {
+	// The ID of the pane, like "%1"
+	Pane string
+	Data string
+}
+
+// A window was created, in the runner's own session or any other
+type WindowAddEvent struct {
+	// The ID of the window, like "@1"
+	Window string
+}
+
+// A session was renamed
+type SessionRenamedEvent struct {
+	// The ID of the session, like "$1"
+	Session string
+	Name    string
+}
+
+// The panes of a window were rearranged or resized
+type LayoutChangeEvent struct {
+	// The ID of the window, like "@1"
+	Window string
+
+	// The layout of the window, as in #{window_layout}, which can be passed
+	// to [Runner.SelectLayout] to restore it
+	Layout string
+
+	// The layout as it is shown, as in #{window_visible_layout}, which
+	// differs from Layout while a pane is zoomed
+	VisibleLayout string
+
+	// The window flags, as in #{window_flags}, like "*Z"
+	Flags string
+}
+
+// tmux stopped sending events to the runner, for example because the server
+// exited or the runner's client was detached. No more events follow unless
+// the runner reconnects.
+type ExitEvent struct {
+	// Why tmux stopped, if it said, like "server exited"
+	Reason string
+}
+
+func (PaneOutputEvent) Type() EventType     { return EventPaneOutput }
+func (WindowAddEvent) Type() EventType      { return EventWindowAdd }
+func (SessionRenamedEvent) Type() EventType { return EventSessionRenamed }
+func (LayoutChangeEvent) Type() EventType   { return EventLayoutChange }
+func (ExitEvent) Type() EventType           { return EventExit }
+
+// Returns the event for a parsed notification, or false if it isn't one of
+// the kinds of event Subscribe reports
+func notificationEvent(n Notification) (Event, bool) {
+	switch n.Name {
+	case "output", "extended-output":
+		if len(n.Args) == 0 {
+			return nil, false
+		}
+		return PaneOutputEvent{Pane: n.Args[0], Data: n.Text}, true
+	case "window-add", "unlinked-window-add":
+		if len(n.Args) != 1 {
+			return nil, false
+		}
+		return WindowAddEvent{Window: n.Args[0]}, true
+	case "session-renamed":
+		if len(n.Args) != 1 {
+			return nil, false
+		}
+		return SessionRenamedEvent{Session: n.Args[0], Name: n.Text}, true
+	case "layout-change":
+		if len(n.Args) < 3 {
+			return nil, false
+		}
+		e := LayoutChangeEvent{Window: n.Args[0], Layout: n.Args[1], VisibleLayout: n.Args[2]}
+		if len(n.Args) > 3 {
+			e.Flags = n.Args[3]
+		}
+		return e, true
+	case "exit":
+		return ExitEvent{Reason: n.Text}, true
+	}
+
+	return nil, false
+}
+
+// Returns a channel which receives events of the given types as tmux reports
+// them, or of every type if none are given, along with a function to call to
+// stop. Events are only reported from the time Subscribe is called, and
+// subscriptions carry over when the runner reconnects. As with
+// [Runner.SubscribeOutput], the caller must keep reading from the channel, and
+// the stop function closes it.
+func (r *Runner) Subscribe(eventTypes ...EventType) (<-chan Event, func()) {
+	wanted := make(map[EventType]bool)
+	for _, t := range eventTypes {
+		wanted[t] = true
+	}
+
+	return subscribeNotifications(r, func(line string) (Event, bool) {
+		n, ok := ParseNotification(line)
+		if !ok {
+			return nil, false
+		}
+
+		e, ok := notificationEvent(n)
+		if !ok || (len(wanted) > 0 && !wanted[e.Type()]) {
+			return nil, false
+		}
+
+		return e, true
+	}, nil)
+}
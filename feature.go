@@ -0,0 +1,87 @@
+package tmux
+
+import "fmt"
+
+// Names of the features reported by [Runner.ServerFeatures]
+const (
+	// The display-popup command
+	FeatureDisplayPopup = "display-popup"
+
+	// Sending keys as hexadecimal with "send-keys -H"
+	FeatureSendKeysHex = "send-keys-hex"
+
+	// Keeping escape sequences for colors and attributes with "capture-pane -e"
+	FeatureCapturePaneEscapes = "capture-pane-escapes"
+
+	// Pane options, set with "set-option -p"
+	FeaturePaneOptions = "pane-options"
+
+	// Inserting a window before another with "new-window -b" or
+	// "move-window -b"
+	FeatureNewWindowBefore = "new-window-before"
+
+	// Flow control for control-mode clients, with the "pause-after" client flag
+	FeaturePauseAfter = "pause-after"
+
+	// Pane titles, set with "select-pane -T"
+	FeaturePaneTitles = "pane-titles"
+
+	// Filtering the output of list commands with "-f"
+	FeatureListFilters = "list-filters"
+)
+
+// The tmux release which introduced each feature
+var featureVersions = map[string]version{
+	FeatureDisplayPopup:       {3, 2},
+	FeatureSendKeysHex:        {3, 0},
+	FeatureCapturePaneEscapes: {1, 8},
+	FeaturePaneOptions:        {3, 0},
+	FeatureNewWindowBefore:    {3, 2},
+	FeaturePauseAfter:         {3, 2},
+	FeaturePaneTitles:         {2, 6},
+	FeatureListFilters:        {2, 6},
+}
+
+// Returns a map from each of the Feature constants to whether tmux supports
+// it, based on the tmux version. The result is computed once and cached for
+// the lifetime of the runner, so don't modify it.
+func (r *Runner) ServerFeatures() (map[string]bool, error) {
+	r.stateMu.Lock()
+	cached := r.features
+	r.stateMu.Unlock()
+
+	if cached != nil {
+		return cached, nil
+	}
+
+	v, err := tmuxVersion(r.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	features := make(map[string]bool)
+	for name, introduced := range featureVersions {
+		features[name] = v.atLeast(introduced.major, introduced.minor)
+	}
+
+	r.stateMu.Lock()
+	r.features = features
+	r.stateMu.Unlock()
+	return features, nil
+}
+
+// Return an error if tmux doesn't support the given feature, saying that what
+// needs it, like "control-mode flow control", requires the tmux release which
+// introduced it
+func (r *Runner) requireFeature(feature string, what string) error {
+	features, err := r.ServerFeatures()
+	if err != nil {
+		return err
+	}
+
+	if !features[feature] {
+		return fmt.Errorf("%s requires tmux %s or later", what, featureVersions[feature])
+	}
+
+	return nil
+}
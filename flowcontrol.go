@@ -0,0 +1,81 @@
+package tmux
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Return an error if tmux doesn't support control-mode flow control
+func (r *Runner) requirePauseAfter() error {
+	return r.requireFeature(FeaturePauseAfter, "control-mode flow control")
+}
+
+// Turn on flow control for the runner's control client: if the %output
+// notifications for a pane fall more than the given number of seconds behind,
+// tmux stops sending them and sends a %pause notification instead, so that a
+// pane producing output faster than it can be read doesn't hold up the
+// output of commands. A paused pane stays paused until it is resumed with
+// [Runner.ResumePane]. A value of 0 turns flow control off.
+//
+// Requires tmux 3.2 or later.
+func (r *Runner) SetPauseAfter(seconds int) error {
+	if err := r.requirePauseAfter(); err != nil {
+		return err
+	}
+
+	var flag string = fmt.Sprintf("pause-after=%d", seconds)
+	if seconds <= 0 {
+		flag = "!pause-after"
+	}
+
+	_, err := r.Run(fmt.Sprintf("refresh-client -f %s", Quote(flag)))
+	return err
+}
+
+// Resume sending %output notifications for a pane paused by flow control. See
+// [Runner.SetPauseAfter].
+func (r *Runner) ResumePane(pane string) error {
+	if err := r.requirePauseAfter(); err != nil {
+		return err
+	}
+
+	_, err := r.Run(fmt.Sprintf("refresh-client -A %s", Quote(pane+":continue")))
+	return err
+}
+
+// Returns the IDs of the panes which tmux has paused because of flow control,
+// and which haven't been resumed since
+func (r *Runner) PausedPanes() []string {
+	r.pausedMu.Lock()
+	defer r.pausedMu.Unlock()
+
+	panes := make([]string, 0, len(r.paused))
+	for pane := range r.paused {
+		panes = append(panes, pane)
+	}
+	sort.Strings(panes)
+
+	return panes
+}
+
+// Keep track of paused panes, from the %pause and %continue notifications
+func (r *Runner) trackFlowControl(line string) {
+	tokens := strings.Split(line, " ")
+	if len(tokens) != 2 {
+		return
+	}
+
+	r.pausedMu.Lock()
+	defer r.pausedMu.Unlock()
+
+	switch tokens[0] {
+	case "%pause":
+		if r.paused == nil {
+			r.paused = make(map[string]bool)
+		}
+		r.paused[tokens[1]] = true
+	case "%continue":
+		delete(r.paused, tokens[1])
+	}
+}
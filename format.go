@@ -0,0 +1,26 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Separates the fields of formats whose values may contain spaces, like names
+// and paths. This is the ASCII unit separator, which won't appear in them.
+const fieldSeparator = "\x1f"
+
+// Join formats, like "#{pane_id}" and "#{pane_current_path}", into a single
+// format whose output can be split again with splitFields
+func joinFields(formats ...string) string {
+	return strings.Join(formats, fieldSeparator)
+}
+
+// Split a line of output from a format built with joinFields into its n fields
+func splitFields(line string, n int) ([]string, error) {
+	fields := strings.Split(line, fieldSeparator)
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected line to have %d fields but found %d: '%s'", n, len(fields), line)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,166 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Build a send-keys command for the given target, with the given extra flags
+func sendKeysCommand(target string, flags string, keys []string) string {
+	var cmd string = "send-keys"
+	if flags != "" {
+		cmd += " " + flags
+	}
+	cmd += fmt.Sprintf(" -t %s", Quote(target))
+
+	for _, key := range keys {
+		cmd += " " + Quote(key)
+	}
+
+	return cmd
+}
+
+// Send keys to the given pane, as if they were typed. Each key is passed to
+// send-keys as a separate argument, so it may be a named key like "Enter" or
+// "C-c", or a string of text like "echo hi", which is typed as it is, spaces
+// included. A string which happens to be the name of a key is sent as that
+// key; use [Runner.SendKeysLiteral] to type text which might be.
+func (r *Runner) SendKeys(target string, keys ...string) error {
+	_, err := r.Run(sendKeysCommand(target, "", keys))
+	return err
+}
+
+// Type the given strings into the given pane as literal text, with
+// "send-keys -l", so that a string like "Enter" is typed as the word rather
+// than pressing the key
+func (r *Runner) SendKeysLiteral(target string, text ...string) error {
+	_, err := r.Run(sendKeysCommand(target, "-l", text))
+	return err
+}
+
+// A key binding, as returned by [Runner.ListKeys]
+type KeyBinding struct {
+	// The key table the binding is in, like "prefix", "root", or "copy-mode"
+	Table string
+
+	// The key, like "c", "C-b", or "MouseDown1Pane"
+	Key string
+
+	// The command the key runs, as tmux shows it, like
+	// "split-window -h" or "command-prompt -p index { select-window -t \":%%\" }"
+	Command string
+
+	// True if the key may be repeated without pressing the prefix key again
+	Repeat bool
+}
+
+// Returns the first field of s, separated by spaces, and the rest of s after
+// the spaces which follow it
+func nextField(s string) (string, string) {
+	s = strings.TrimLeft(s, " ")
+	field, rest, _ := strings.Cut(s, " ")
+	return field, strings.TrimLeft(rest, " ")
+}
+
+// Returns a key as list-keys shows it, quoted for the tmux parser if need be,
+// as it is pressed. A single character which is special to the parser, like
+// ";" or "#", is shown escaped with a backslash; other keys with special
+// characters, like M-{, are shown in double quotes, with backslash escapes
+// inside them, or in single quotes.
+func unquoteKey(key string) string {
+	if len(key) == 2 && key[0] == '\\' {
+		return key[1:]
+	}
+
+	if len(key) >= 2 && key[0] == '\'' && key[len(key)-1] == '\'' {
+		return key[1 : len(key)-1]
+	}
+
+	if len(key) < 2 || key[0] != '"' || key[len(key)-1] != '"' {
+		return key
+	}
+
+	quoted := key[1 : len(key)-1]
+
+	var b strings.Builder
+	for i := 0; i < len(quoted); i++ {
+		c := quoted[i]
+		if c != '\\' || i+1 >= len(quoted) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch {
+		case i+2 < len(quoted) && isOctalDigit(quoted[i]) && isOctalDigit(quoted[i+1]) && isOctalDigit(quoted[i+2]):
+			b.WriteByte((quoted[i]-'0')<<6 | (quoted[i+1]-'0')<<3 | (quoted[i+2] - '0'))
+			i += 2
+		case quoted[i] == 'n':
+			b.WriteByte('\n')
+		case quoted[i] == 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(quoted[i])
+		}
+	}
+
+	return b.String()
+}
+
+// Parse a line of list-keys output, like
+// "bind-key -r -T prefix Up select-pane -U"
+func parseKeyBinding(line string) (KeyBinding, error) {
+	var b KeyBinding
+
+	field, rest := nextField(line)
+	if field != "bind-key" {
+		return KeyBinding{}, fmt.Errorf("expected key binding to start with 'bind-key' but found '%s'", line)
+	}
+
+	field, rest = nextField(rest)
+	if field == "-r" {
+		b.Repeat = true
+		field, rest = nextField(rest)
+	}
+
+	if field != "-T" {
+		return KeyBinding{}, fmt.Errorf("expected key binding to have a key table but found '%s'", line)
+	}
+	b.Table, rest = nextField(rest)
+
+	var key string
+	key, b.Command = nextField(rest)
+	b.Key = unquoteKey(key)
+
+	if b.Table == "" || b.Key == "" || b.Command == "" {
+		return KeyBinding{}, fmt.Errorf("expected key binding to have a table, key, and command but found '%s'", line)
+	}
+
+	return b, nil
+}
+
+// Returns the key bindings in every key table
+func (r *Runner) ListKeys() ([]KeyBinding, error) {
+	output, err := r.Run("list-keys")
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := make([]KeyBinding, 0)
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		b, err := parseKeyBinding(line)
+		if err != nil {
+			return nil, err
+		}
+
+		bindings = append(bindings, b)
+	}
+
+	return bindings, nil
+}
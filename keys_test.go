@@ -0,0 +1,86 @@
+package tmux
+
+import "testing"
+
+func TestParseKeyBinding(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected KeyBinding
+	}{
+		{
+			"bind-key    -T prefix       c                    new-window",
+			KeyBinding{Table: "prefix", Key: "c", Command: "new-window"},
+		},
+		{
+			"bind-key -r -T prefix       Up                   select-pane -U",
+			KeyBinding{Table: "prefix", Key: "Up", Command: "select-pane -U", Repeat: true},
+		},
+		{
+			`bind-key    -T prefix       \;                   last-pane`,
+			KeyBinding{Table: "prefix", Key: ";", Command: "last-pane"},
+		},
+		{
+			`bind-key    -T prefix       \#                   list-buffers`,
+			KeyBinding{Table: "prefix", Key: "#", Command: "list-buffers"},
+		},
+		{
+			`bind-key    -T prefix       \\                   split-window -h`,
+			KeyBinding{Table: "prefix", Key: `\`, Command: "split-window -h"},
+		},
+		{
+			`bind-key    -T copy-mode    "M-{"                send-keys -X previous-paragraph`,
+			KeyBinding{Table: "copy-mode", Key: "M-{", Command: "send-keys -X previous-paragraph"},
+		},
+		{
+			`bind-key    -T copy-mode    "M-}"                send-keys -X next-paragraph`,
+			KeyBinding{Table: "copy-mode", Key: "M-}", Command: "send-keys -X next-paragraph"},
+		},
+		{
+			`bind-key    -T prefix       "M-$"               list-sessions`,
+			KeyBinding{Table: "prefix", Key: "M-$", Command: "list-sessions"},
+		},
+		{
+			`bind-key    -T prefix       'M-"'                list-windows`,
+			KeyBinding{Table: "prefix", Key: `M-"`, Command: "list-windows"},
+		},
+		{
+			`bind-key    -T prefix       \"                   split-window`,
+			KeyBinding{Table: "prefix", Key: `"`, Command: "split-window"},
+		},
+		{
+			`bind-key    -T copy-mode-vi v                    send-keys -X begin-selection`,
+			KeyBinding{Table: "copy-mode-vi", Key: "v", Command: "send-keys -X begin-selection"},
+		},
+		{
+			`bind-key    -T root         MouseDown1Pane       select-pane -t = \; send-keys -M`,
+			KeyBinding{Table: "root", Key: "MouseDown1Pane", Command: `select-pane -t = \; send-keys -M`},
+		},
+	}
+
+	for _, test := range tests {
+		actual, err := parseKeyBinding(test.line)
+		if err != nil {
+			t.Errorf("parseKeyBinding(%q) returned error: %v", test.line, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("parseKeyBinding(%q): expected %#v but found %#v", test.line, test.expected, actual)
+		}
+	}
+}
+
+func TestParseKeyBindingInvalid(t *testing.T) {
+	lines := []string{
+		"",
+		"unbind-key c",
+		"bind-key c new-window",
+		"bind-key -T prefix",
+		"bind-key -T prefix c",
+	}
+
+	for _, line := range lines {
+		if b, err := parseKeyBinding(line); err == nil {
+			t.Errorf("parseKeyBinding(%q): expected an error but found %#v", line, b)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package tmux
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A cell of a window layout, as in #{window_layout}: either a pane, or a
+// split containing other cells side by side or one above the other
+type layoutCell struct {
+	width, height int
+	x, y          int
+
+	// The pane ID, like "%3", if this cell is a pane
+	pane string
+
+	// '{' if the children are side by side, '[' if they are one above the
+	// other, or 0 if this cell is a pane
+	split    byte
+	children []*layoutCell
+}
+
+// Parse a layout string, like
+// "46f6,80x24,0,0{40x24,0,0,0,39x24,41,0,1}", as reported by #{window_layout}
+func parseLayout(s string) (*layoutCell, error) {
+	_, body, found := strings.Cut(s, ",")
+	if !found {
+		return nil, fmt.Errorf("expected layout to start with a checksum but found '%s'", s)
+	}
+
+	cell, rest, err := parseLayoutCell(body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing layout '%s': '%s'", s, err.Error())
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected '%s' at end of layout '%s'", rest, s)
+	}
+
+	return cell, nil
+}
+
+// Read the number at the start of s, and return it and the rest of s
+func readLayoutNumber(s string) (int, string, error) {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+
+	n, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return 0, "", fmt.Errorf("expected a number at '%s'", s)
+	}
+
+	return n, s[end:], nil
+}
+
+// Parse the cell at the start of s, like "80x24,0,0,1" for a pane, and return
+// it and the rest of s
+func parseLayoutCell(s string) (*layoutCell, string, error) {
+	var err error
+
+	c := &layoutCell{}
+	rest := s
+
+	// The size and offset, "80x24,0,0", with these separators between them
+	values := []*int{&c.width, &c.height, &c.x, &c.y}
+	separators := []byte{'x', ',', ',', 0}
+	for i, value := range values {
+		if *value, rest, err = readLayoutNumber(rest); err != nil {
+			return nil, "", err
+		}
+		if separators[i] != 0 {
+			if rest == "" || rest[0] != separators[i] {
+				return nil, "", fmt.Errorf("expected '%c' at '%s'", separators[i], rest)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	if rest != "" && (rest[0] == '{' || rest[0] == '[') {
+		c.split = rest[0]
+		closing := byte('}')
+		if c.split == '[' {
+			closing = ']'
+		}
+		rest = rest[1:]
+
+		for {
+			var child *layoutCell
+			if child, rest, err = parseLayoutCell(rest); err != nil {
+				return nil, "", err
+			}
+			c.children = append(c.children, child)
+
+			if rest == "" {
+				return nil, "", fmt.Errorf("missing '%c'", closing)
+			}
+			if rest[0] == closing {
+				return c, rest[1:], nil
+			}
+			if rest[0] != ',' {
+				return nil, "", fmt.Errorf("expected ',' or '%c' at '%s'", closing, rest)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	// A pane, followed by its ID without the "%"
+	if rest == "" || rest[0] != ',' {
+		return nil, "", fmt.Errorf("expected a pane ID at '%s'", rest)
+	}
+
+	var id int
+	if id, rest, err = readLayoutNumber(rest[1:]); err != nil {
+		return nil, "", err
+	}
+	c.pane = fmt.Sprintf("%%%d", id)
+
+	return c, rest, nil
+}
+
+// Returns the cell as it appears in a layout string, without the checksum
+func (c *layoutCell) String() string {
+	s := fmt.Sprintf("%dx%d,%d,%d", c.width, c.height, c.x, c.y)
+
+	if c.split == 0 {
+		return s + "," + strings.TrimPrefix(c.pane, "%")
+	}
+
+	closing := "}"
+	if c.split == '[' {
+		closing = "]"
+	}
+
+	children := make([]string, len(c.children))
+	for i, child := range c.children {
+		children[i] = child.String()
+	}
+
+	return s + string(c.split) + strings.Join(children, ",") + closing
+}
+
+// Returns the layout string for the cell, with the checksum tmux expects
+func (c *layoutCell) layout() string {
+	body := c.String()
+
+	var sum uint16
+	for i := 0; i < len(body); i++ {
+		sum = (sum >> 1) + ((sum & 1) << 15)
+		sum += uint16(body[i])
+	}
+
+	return fmt.Sprintf("%04x,%s", sum, body)
+}
+
+// Returns the first pane in the cell, which is the one at its top left
+func (c *layoutCell) firstPane() string {
+	for c.split != 0 {
+		c = c.children[0]
+	}
+	return c.pane
+}
+
+// Change the width of the cell, and of the cells in it, which in a
+// side-by-side split share the change in proportion to their widths
+func (c *layoutCell) setWidth(width int) {
+	switch c.split {
+	case '[':
+		for _, child := range c.children {
+			child.setWidth(width)
+		}
+	case '{':
+		// Each child after the first is preceded by a one-cell border
+		borders := len(c.children) - 1
+		old := c.width - borders
+		available := width - borders
+
+		remaining := available
+		for i, child := range c.children {
+			w := remaining
+			if i < len(c.children)-1 {
+				w = child.width * available / old
+				if w < 1 {
+					w = 1
+				}
+			}
+			remaining -= w
+			child.setWidth(w)
+		}
+	}
+
+	c.width = width
+}
+
+// Change the left edge of the cell, and lay out the cells in it from there
+func (c *layoutCell) setX(x int) {
+	c.x = x
+
+	for _, child := range c.children {
+		child.setX(x)
+		if c.split == '{' {
+			x += child.width + 1
+		}
+	}
+}
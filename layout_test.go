@@ -0,0 +1,116 @@
+package tmux
+
+import "testing"
+
+// Layouts reported by tmux 3.3a for an 80x24 window
+var layoutTests = []string{
+	"b25d,80x24,0,0,0",
+	"8205,80x24,0,0{40x24,0,0,0,39x24,41,0,1}",
+	"c195,80x24,0,0[80x12,0,0,0,80x11,0,13,1]",
+	"d67e,80x24,0,0{40x24,0,0,0,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}",
+	"ff26,80x24,0,0{20x24,0,0,0,19x24,21,0,3,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}",
+}
+
+func TestParseLayoutRoundTrip(t *testing.T) {
+	for _, layout := range layoutTests {
+		cell, err := parseLayout(layout)
+		if err != nil {
+			t.Errorf("parseLayout(%q) returned error: %v", layout, err)
+			continue
+		}
+
+		// The checksum is recomputed, so this also checks it matches tmux's
+		if actual := cell.layout(); actual != layout {
+			t.Errorf("parseLayout(%q).layout(): expected the same layout but found %q", layout, actual)
+		}
+	}
+}
+
+func TestParseLayoutCells(t *testing.T) {
+	cell, err := parseLayout("ff26,80x24,0,0{20x24,0,0,0,19x24,21,0,3,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}")
+	if err != nil {
+		t.Fatalf("parseLayout returned error: %v", err)
+	}
+
+	if cell.split != '{' || len(cell.children) != 3 {
+		t.Fatalf("expected a side-by-side split of 3 cells but found %q with %d", cell.split, len(cell.children))
+	}
+	if cell.width != 80 || cell.height != 24 {
+		t.Errorf("expected an 80x24 window but found %dx%d", cell.width, cell.height)
+	}
+
+	second := cell.children[1]
+	if second.pane != "%3" || second.width != 19 || second.x != 21 {
+		t.Errorf("expected pane %%3, 19 wide at 21, but found %s, %d wide at %d", second.pane, second.width, second.x)
+	}
+
+	third := cell.children[2]
+	if third.split != '[' || len(third.children) != 2 {
+		t.Fatalf("expected a split of 2 cells one above the other but found %q with %d", third.split, len(third.children))
+	}
+	if bottom := third.children[1]; bottom.pane != "%2" || bottom.y != 13 || bottom.height != 11 {
+		t.Errorf("expected pane %%2, 11 high at 13, but found %s, %d high at %d", bottom.pane, bottom.height, bottom.y)
+	}
+
+	if first := cell.firstPane(); first != "%0" {
+		t.Errorf("expected first pane %%0 but found %s", first)
+	}
+	if first := third.firstPane(); first != "%1" {
+		t.Errorf("expected first pane of the third column %%1 but found %s", first)
+	}
+}
+
+func TestParseLayoutInvalid(t *testing.T) {
+	layouts := []string{
+		"",
+		"80x24,0,0,0",
+		"b25d,80x24,0,0",
+		"b25d,80x24,0,0,0,",
+		"b25d,80,0,0,0",
+		"8205,80x24,0,0{40x24,0,0,0,39x24,41,0,1",
+		"8205,80x24,0,0{40x24,0,0,0,39x24,41,0,1]",
+	}
+
+	for _, layout := range layouts {
+		if cell, err := parseLayout(layout); err == nil {
+			t.Errorf("parseLayout(%q): expected an error but found %q", layout, cell.String())
+		}
+	}
+}
+
+func TestLayoutSetWidth(t *testing.T) {
+	tests := []struct {
+		layout   string
+		column   int
+		width    int
+		expected string
+	}{
+		// Resizing a column of panes one above the other resizes each pane
+		{
+			"d67e,80x24,0,0{40x24,0,0,0,39x24,41,0[39x12,41,0,1,39x11,41,13,2]}",
+			1, 30,
+			"80x24,0,0{40x24,0,0,0,30x24,41,0[30x12,41,0,1,30x11,41,13,2]}",
+		},
+		// Resizing a side-by-side split shares the width out in proportion,
+		// leaving a border between each pair of cells
+		{
+			"d67e,80x24,0,0{40x24,0,0,0,39x24,41,0{19x24,41,0,1,19x24,61,0,2}}",
+			1, 29,
+			"80x24,0,0{40x24,0,0,0,29x24,41,0{14x24,41,0,1,14x24,56,0,2}}",
+		},
+	}
+
+	for _, test := range tests {
+		root, err := parseLayout(test.layout)
+		if err != nil {
+			t.Fatalf("parseLayout(%q) returned error: %v", test.layout, err)
+		}
+
+		root.children[test.column].setWidth(test.width)
+		root.setX(root.x)
+
+		if actual := root.String(); actual != test.expected {
+			t.Errorf("setWidth(%d) on column %d of %q: expected %q but found %q", test.width, test.column, test.layout, test.expected, actual)
+		}
+	}
+}
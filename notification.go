@@ -0,0 +1,295 @@
+package tmux
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// A control-mode notification, which tmux sends outside the output of any
+// command to report a change, like "%window-add @1", as parsed by
+// [ParseNotification]
+type Notification struct {
+	// The kind of notification, without the "%", like "window-add"
+	Name string
+
+	// The IDs and other single-word values which follow the name, like "@1"
+	Args []string
+
+	// The free text at the end of the notification, if its kind has one, like
+	// the new name in "%window-renamed @1 my window", the message in
+	// "%message ...", or the output in "%output %1 ...", with the escapes tmux
+	// puts in output decoded
+	Text string
+}
+
+// The number of arguments before the free text of each kind of notification
+// which has free text
+var notificationTextArgs = map[string]int{
+	"client-session-changed":  2,
+	"config-error":            0,
+	"exit":                    0,
+	"message":                 0,
+	"output":                  1,
+	"paste-buffer-changed":    0,
+	"paste-buffer-deleted":    0,
+	"session-changed":         1,
+	"session-renamed":         1,
+	"unlinked-window-renamed": 1,
+	"window-renamed":          1,
+}
+
+// Parse a control-mode notification line, like "%window-renamed @1 my window".
+// Returns false if the line isn't a notification.
+//
+// Notifications with free text at the end are split into their arguments and
+// the text, so text with spaces in it is kept whole; so are
+// %extended-output and %subscription-changed, whose text follows " : ". Other
+// notifications, including any kind this package doesn't know of, are split
+// into words, all of which are arguments.
+func ParseNotification(line string) (Notification, bool) {
+	if !strings.HasPrefix(line, "%") {
+		return Notification{}, false
+	}
+
+	// The lines around the output of a command aren't notifications
+	for _, marker := range []string{tmuxBeginMarker, tmuxEndMarker, tmuxErrorMarker} {
+		if strings.HasPrefix(line, marker+" ") {
+			return Notification{}, false
+		}
+	}
+
+	name, rest, _ := strings.Cut(line[1:], " ")
+	n := Notification{Name: name, Args: make([]string, 0)}
+
+	switch name {
+	case "extended-output", "subscription-changed":
+		var text string
+		rest, text, _ = strings.Cut(rest, " : ")
+		n.Args = append(n.Args, strings.Fields(rest)...)
+		n.Text = text
+	default:
+		count, hasText := notificationTextArgs[name]
+		if !hasText {
+			n.Args = append(n.Args, strings.Fields(rest)...)
+			break
+		}
+
+		for i := 0; i < count && rest != ""; i++ {
+			var arg string
+			arg, rest, _ = strings.Cut(rest, " ")
+			n.Args = append(n.Args, arg)
+		}
+		n.Text = rest
+	}
+
+	if name == "output" || name == "extended-output" {
+		n.Text = decodeOutput(n.Text)
+	}
+
+	return n, true
+}
+
+// Register fn to be called with each notification line, from the goroutine
+// which reads from tmux, in addition to OnNotification. Returns a function
+// which unregisters it.
+func (r *Runner) addNotificationListener(fn func(line string)) func() {
+	r.listenersMu.Lock()
+	defer r.listenersMu.Unlock()
+
+	if r.listeners == nil {
+		r.listeners = make(map[int]func(string))
+	}
+
+	id := r.nextListener
+	r.nextListener++
+	r.listeners[id] = fn
+
+	return func() {
+		r.listenersMu.Lock()
+		defer r.listenersMu.Unlock()
+
+		delete(r.listeners, id)
+	}
+}
+
+// How many values a subscription holds for the caller before the runner stops
+// reading from tmux to wait for the caller to catch up
+const subscriptionBacklog = 256
+
+// Returns a channel which receives the value parse returns for each
+// notification line it accepts, along with a function to call to stop. The
+// stop function closes the channel, then calls onStop, if it isn't nil; it may
+// be called more than once, but does this only the first time.
+func subscribeNotifications[T any](r *Runner, parse func(line string) (T, bool), onStop func()) (<-chan T, func()) {
+	out := make(chan T, subscriptionBacklog)
+	stop := make(chan struct{})
+
+	// Held while sending to out, so that it isn't closed during a send
+	var mu sync.Mutex
+	stopped := false
+
+	remove := r.addNotificationListener(func(line string) {
+		value, ok := parse(line)
+		if !ok {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if stopped {
+			return
+		}
+
+		select {
+		case out <- value:
+		case <-stop:
+		}
+	})
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			remove()
+			close(stop)
+
+			mu.Lock()
+			stopped = true
+			close(out)
+			mu.Unlock()
+
+			if onStop != nil {
+				onStop()
+			}
+		})
+	}
+
+	return out, cancel
+}
+
+// Handle a notification line, and pass it to OnNotification,
+// OnParsedNotification and the registered listeners
+func (r *Runner) dispatchNotification(line string) {
+	r.trackFlowControl(line)
+
+	if r.OnNotification != nil {
+		r.OnNotification(line)
+	}
+
+	if r.OnParsedNotification != nil {
+		if n, ok := ParseNotification(line); ok {
+			r.OnParsedNotification(n)
+		}
+	}
+
+	r.listenersMu.Lock()
+	listeners := make([]func(string), 0, len(r.listeners))
+	for _, fn := range r.listeners {
+		listeners = append(listeners, fn)
+	}
+	r.listenersMu.Unlock()
+
+	for _, fn := range listeners {
+		fn(line)
+	}
+}
+
+// Call fn with the ID of each window created from now until ctx is done, by
+// this or any other client, then return ctx.Err(). Windows which already
+// exist aren't passed to fn; see [Runner.WatchAllWindows] to include them.
+//
+// This uses the %window-add and %unlinked-window-add notifications, which
+// tmux sends for new windows in the runner's own session and in other
+// sessions. fn is called synchronously, from the goroutine calling
+// WatchNewWindows, so it may use the Runner, for example to set options on the
+// new window; while it runs, further window IDs are queued for it, however
+// many there are, so that the runner never waits on fn to read from tmux.
+func (r *Runner) WatchNewWindows(ctx context.Context, fn func(windowID string)) error {
+	return r.watchWindows(ctx, nil, fn)
+}
+
+// Like [Runner.WatchNewWindows], but first calls fn with the ID of each window
+// which already exists, in every session. Each window is passed to fn once,
+// even if it is created while the existing windows are being listed.
+func (r *Runner) WatchAllWindows(ctx context.Context, fn func(windowID string)) error {
+	return r.watchWindows(ctx, func() ([]string, error) {
+		windows, err := r.AllWindows()
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]string, 0, len(windows))
+		for _, w := range windows {
+			ids = append(ids, w.ID)
+		}
+		return ids, nil
+	}, fn)
+}
+
+// Call fn with the IDs returned by existing, if it isn't nil, and then with
+// the ID of each new window until ctx is done
+func (r *Runner) watchWindows(ctx context.Context, existing func() ([]string, error), fn func(windowID string)) error {
+	// The queue has no limit: if the listener blocked when it was full, and
+	// fn was waiting on the runner, which was waiting on the listener to read
+	// tmux's output, neither would ever finish
+	var mu sync.Mutex
+	var queued []string
+	ready := make(chan struct{}, 1)
+
+	remove := r.addNotificationListener(func(line string) {
+		n, ok := ParseNotification(line)
+		if !ok || (n.Name != "window-add" && n.Name != "unlinked-window-add") || len(n.Args) != 1 {
+			return
+		}
+
+		mu.Lock()
+		queued = append(queued, n.Args[0])
+		mu.Unlock()
+
+		select {
+		case ready <- struct{}{}:
+		default:
+		}
+	})
+	defer remove()
+
+	seen := make(map[string]bool)
+
+	if existing != nil {
+		ids, err := existing()
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				fn(id)
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ready:
+		}
+
+		mu.Lock()
+		ids := queued
+		queued = nil
+		mu.Unlock()
+
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !seen[id] {
+				seen[id] = true
+				fn(id)
+			}
+		}
+	}
+}
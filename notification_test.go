@@ -0,0 +1,107 @@
+package tmux
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseNotification(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Notification
+	}{
+		{"%window-add @1", Notification{Name: "window-add", Args: []string{"@1"}}},
+		{"%sessions-changed", Notification{Name: "sessions-changed", Args: []string{}}},
+		{"%window-renamed @1 my window", Notification{Name: "window-renamed", Args: []string{"@1"}, Text: "my window"}},
+		{"%session-changed $0 work", Notification{Name: "session-changed", Args: []string{"$0"}, Text: "work"}},
+		{"%session-renamed $1 a  b", Notification{Name: "session-renamed", Args: []string{"$1"}, Text: "a  b"}},
+		{"%client-session-changed /dev/pts/1 $1 work", Notification{Name: "client-session-changed", Args: []string{"/dev/pts/1", "$1"}, Text: "work"}},
+		{"%message hello there", Notification{Name: "message", Args: []string{}, Text: "hello there"}},
+		{"%exit", Notification{Name: "exit", Args: []string{}}},
+		{"%exit server exited", Notification{Name: "exit", Args: []string{}, Text: "server exited"}},
+		{`%output %3 a\015\012b c`, Notification{Name: "output", Args: []string{"%3"}, Text: "a\r\nb c"}},
+		{`%extended-output %3 10 : hi there\012`, Notification{Name: "extended-output", Args: []string{"%3", "10"}, Text: "hi there\n"}},
+		{"%subscription-changed s $1 @1 0 %1 : a : b", Notification{Name: "subscription-changed", Args: []string{"s", "$1", "@1", "0", "%1"}, Text: "a : b"}},
+		{"%layout-change @1 b25d,80x24,0,0,0 b25d,80x24,0,0,0 *Z", Notification{Name: "layout-change", Args: []string{"@1", "b25d,80x24,0,0,0", "b25d,80x24,0,0,0", "*Z"}}},
+		{"%pause %1", Notification{Name: "pause", Args: []string{"%1"}}},
+		{"%some-future-notification a b", Notification{Name: "some-future-notification", Args: []string{"a", "b"}}},
+	}
+
+	for _, test := range tests {
+		actual, ok := ParseNotification(test.line)
+		if !ok {
+			t.Errorf("ParseNotification(%q): expected a notification", test.line)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("ParseNotification(%q): expected %#v but found %#v", test.line, test.expected, actual)
+		}
+	}
+}
+
+func TestParseNotificationNotANotification(t *testing.T) {
+	lines := []string{
+		"",
+		"hello",
+		"%begin 1792110834 263 1",
+		"%end 1792110834 263 1",
+		"%error 1792110834 263 1",
+	}
+
+	for _, line := range lines {
+		if n, ok := ParseNotification(line); ok {
+			t.Errorf("ParseNotification(%q): expected no notification but found %#v", line, n)
+		}
+	}
+}
+
+// fn may use the Runner, even while more windows are created than would fit in
+// any fixed queue
+func TestWatchNewWindowsUsingRunner(t *testing.T) {
+	r := newTestRunner(t, Config{})
+
+	const windows = 300
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// The windows run sleep rather than a shell, which is much quicker to start
+	if _, err := r.Run("new-session -d -s other 'sleep 60'"); err != nil {
+		t.Fatalf("new-session returned error: %v", err)
+	}
+
+	seen := 0
+	go func() {
+		// Wait until WatchNewWindows is listening
+		time.Sleep(100 * time.Millisecond)
+		_, _ = r.Run("new-window -d -t other: 'sleep 60'")
+	}()
+
+	err := r.WatchNewWindows(ctx, func(windowID string) {
+		seen++
+
+		if seen == 1 {
+			cmds := make([]string, windows)
+			for i := range cmds {
+				cmds[i] = "new-window -d -t other: 'sleep 60'"
+			}
+			if _, err := r.RunBatch(cmds); err != nil {
+				t.Errorf("RunBatch returned error: %v", err)
+			}
+		}
+
+		if _, err := r.Run("display-message -p -t " + Quote(windowID) + " ok"); err != nil {
+			t.Errorf("Run returned error: %v", err)
+		}
+
+		if seen == windows+1 {
+			cancel()
+		}
+	})
+
+	if seen != windows+1 {
+		t.Errorf("expected %d windows but found %d, %v", windows+1, seen, err)
+	}
+}
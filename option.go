@@ -0,0 +1,195 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The kind of a tmux option, which determines the flag used to get or set it
+type OptionKind int
+
+const (
+	// Let tmux infer the kind of option from its name. User options (those
+	// starting with "@") are treated as session options.
+	InferredOption OptionKind = iota
+
+	// A server option, set with "set-option -s"
+	ServerOption
+
+	// A session option, set with "set-option" and no kind flag
+	SessionOption
+
+	// A window option, set with "set-option -w"
+	WindowOption
+
+	// A pane option, set with "set-option -p"
+	PaneOption
+)
+
+// Identifies which set of options an option is read from or written to.
+//
+// The zero value refers to the options of the runner's current session, with
+// tmux inferring the kind of option from its name.
+type OptionScope struct {
+	// The kind of option
+	Kind OptionKind
+
+	// The session, window, or pane whose options to use. Ignored for server
+	// options, and when Global is true.
+	Target string
+
+	// If true, use the global session or window options, which targets inherit
+	// from unless they set their own value
+	Global bool
+}
+
+// Returns the flags selecting this scope, for use with set-option and
+// show-options
+func (s OptionScope) flags() string {
+	flags := make([]string, 0)
+
+	switch s.Kind {
+	case ServerOption:
+		flags = append(flags, "-s")
+	case WindowOption:
+		flags = append(flags, "-w")
+	case PaneOption:
+		flags = append(flags, "-p")
+	}
+
+	if s.Global {
+		flags = append(flags, "-g")
+	} else if s.Target != "" && s.Kind != ServerOption {
+		flags = append(flags, fmt.Sprintf("-t %s", Quote(s.Target)))
+	}
+
+	return strings.Join(flags, " ")
+}
+
+// Set the option with the given name in the given scope
+func (r *Runner) SetOption(scope OptionScope, name, value string) error {
+	var cmd string = fmt.Sprintf("set-option %s %s %s", scope.flags(), Quote(name), Quote(value))
+
+	_, err := r.Run(cmd)
+	return err
+}
+
+// Set the option with the given name in the given scope, unless it already has
+// a value there. This uses "set-option -o", so the check and the write happen
+// atomically in the tmux server.
+//
+// Only options set directly in the scope count as having a value, so this is
+// useful for providing defaults for a session, window, or pane without
+// overriding what the user has configured for it. All scopes accept -o, but
+// built-in server and global options always have a value, so for those this
+// never changes anything; user options (starting with "@") can be defaulted in
+// any scope.
+//
+// An option which is already set is not treated as an error.
+func (r *Runner) SetOptionIfUnset(scope OptionScope, name, value string) error {
+	var cmd string = fmt.Sprintf("set-option -o %s %s %s", scope.flags(), Quote(name), Quote(value))
+
+	if _, err := r.Run(cmd); err != nil {
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) && strings.HasPrefix(cmdErr.Message, "already set: ") {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Get the value of the option with the given name in the given scope. If the
+// option isn't set directly in the scope, for example a session option which
+// the session inherits from the global options, returns an empty string.
+// Returns a [CommandError] if tmux has no option with that name.
+func (r *Runner) GetOption(scope OptionScope, name string) (string, error) {
+	var cmd string = fmt.Sprintf("show-options -v %s %s", scope.flags(), Quote(name))
+
+	output, err := r.Run(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return Trim(output), nil
+}
+
+// Unset the option with the given name in the given scope. A session, window,
+// or pane option which is unset inherits its value from the global options.
+func (r *Runner) UnsetOption(scope OptionScope, name string) error {
+	var cmd string = fmt.Sprintf("set-option -u %s %s", scope.flags(), Quote(name))
+
+	_, err := r.Run(cmd)
+	return err
+}
+
+// Returns true if the option with the given name is set directly in the given
+// scope, rather than being inherited
+func (r *Runner) isOptionSet(scope OptionScope, name string) (bool, error) {
+	// Without -q, tmux reports a user option which isn't set, like "@foo", as
+	// an invalid option
+	var cmd string = fmt.Sprintf("show-options -q %s %s", scope.flags(), Quote(name))
+
+	output, err := r.Run(cmd)
+	if err != nil {
+		return false, err
+	}
+
+	return Trim(output) != "", nil
+}
+
+// Set the options in temp to the given values in the given scope, run fn, then
+// put the options back the way they were, even if fn returns an error or
+// panics. Options which were set in the scope get their old values back, and
+// options which weren't are unset again.
+//
+// Returns the error from fn if there is one, otherwise any error from applying
+// or restoring the options.
+func (r *Runner) WithOptions(scope OptionScope, temp map[string]string, fn func() error) (err error) {
+	type savedOption struct {
+		name  string
+		value string
+		set   bool
+	}
+
+	saved := make([]savedOption, 0, len(temp))
+	for name := range temp {
+		s := savedOption{name: name}
+
+		if s.set, err = r.isOptionSet(scope, name); err != nil {
+			return err
+		}
+		if s.set {
+			if s.value, err = r.GetOption(scope, name); err != nil {
+				return err
+			}
+		}
+
+		saved = append(saved, s)
+	}
+
+	defer func() {
+		for _, s := range saved {
+			var restoreErr error
+			if s.set {
+				restoreErr = r.SetOption(scope, s.name, s.value)
+			} else {
+				restoreErr = r.UnsetOption(scope, s.name)
+			}
+
+			if restoreErr != nil && err == nil {
+				err = fmt.Errorf("error restoring option '%s': '%s'", s.name, restoreErr.Error())
+			}
+		}
+	}()
+
+	for name, value := range temp {
+		if err = r.SetOption(scope, name, value); err != nil {
+			return err
+		}
+	}
+
+	return fn()
+}
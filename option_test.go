@@ -0,0 +1,50 @@
+package tmux
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithOptionsUnsetUserOption(t *testing.T) {
+	r := newTestRunner(t, Config{})
+	scope := OptionScope{Kind: SessionOption, Global: true}
+
+	var during string
+	err := r.WithOptions(scope, map[string]string{"@foo": "temporary"}, func() error {
+		var err error
+		during, err = r.GetOption(scope, "@foo")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithOptions returned error: %v", err)
+	}
+
+	if during != "temporary" {
+		t.Errorf("expected @foo to be %q while fn ran but found %q", "temporary", during)
+	}
+
+	if set, err := r.isOptionSet(scope, "@foo"); err != nil || set {
+		t.Errorf("expected @foo to be unset again but found %v, %v", set, err)
+	}
+}
+
+func TestWithOptionsRestoresValue(t *testing.T) {
+	r := newTestRunner(t, Config{})
+	scope := OptionScope{Kind: SessionOption, Global: true}
+
+	if err := r.SetOption(scope, "@foo", "original value"); err != nil {
+		t.Fatalf("SetOption returned error: %v", err)
+	}
+
+	errFn := errors.New("fn failed")
+	err := r.WithOptions(scope, map[string]string{"@foo": "temporary"}, func() error {
+		return errFn
+	})
+	if !errors.Is(err, errFn) {
+		t.Errorf("expected the error from fn but found %v", err)
+	}
+
+	if value, err := r.GetOption(scope, "@foo"); err != nil || value != "original value" {
+		t.Errorf("expected @foo to be %q again but found %q, %v", "original value", value, err)
+	}
+}
@@ -0,0 +1,150 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Decode the data of an %output notification, in which tmux writes
+// backslashes and characters below space as three-digit octal escapes, like
+// "\015\012" for "\r\n"
+func decodeOutput(data string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(data); i++ {
+		if data[i] == '\\' && i+3 < len(data) && isOctalDigit(data[i+1]) && isOctalDigit(data[i+2]) && isOctalDigit(data[i+3]) {
+			b.WriteByte((data[i+1]-'0')<<6 | (data[i+2]-'0')<<3 | (data[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(data[i])
+	}
+
+	return b.String()
+}
+
+func isOctalDigit(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// If line is an %output or %extended-output notification for the given pane,
+// returns its decoded data
+func parseOutputNotification(line, pane string) (string, bool) {
+	n, ok := ParseNotification(line)
+	if !ok || (n.Name != "output" && n.Name != "extended-output") {
+		return "", false
+	}
+
+	// With flow control on, output arrives as "%extended-output %1 age ... : data"
+	if len(n.Args) == 0 || n.Args[0] != pane {
+		return "", false
+	}
+
+	return n.Text, true
+}
+
+// Returns a channel which receives the output of the given pane as it is
+// written, in chunks as tmux reports them, along with a function to call to
+// stop. The chunks are the raw bytes the program in the pane wrote, escape
+// sequences and all, not the text on the screen; see [Runner.CapturePane] for
+// that.
+//
+// tmux only sends the output of panes in windows in the runner's own session,
+// so if the pane's window isn't there, it is linked into it until the last
+// subscription for the window is stopped. This doesn't change the window's
+// size or which sessions it belongs to otherwise.
+//
+// The caller must keep reading from the channel: once it holds 256 chunks,
+// the runner stops reading from tmux until there is room. The stop function
+// closes the channel, and may be called more than once; since it may run a
+// command, don't call it from OnNotification.
+func (r *Runner) SubscribeOutput(pane string) (<-chan string, func(), error) {
+	var err error
+
+	// Notifications name the pane by ID, whatever form of target was given
+	var output string
+	if output, err = r.displayMessage(pane, joinFields("#{pane_id}", "#{window_id}")); err != nil {
+		return nil, nil, err
+	}
+
+	var fields []string
+	if fields, err = splitFields(output, 2); err != nil {
+		return nil, nil, err
+	}
+	paneID, windowID := fields[0], fields[1]
+
+	var linked bool
+	if linked, err = r.linkForOutput(windowID); err != nil {
+		return nil, nil, err
+	}
+
+	var unlink func()
+	if linked {
+		unlink = func() { r.unlinkForOutput(windowID) }
+	}
+
+	out, cancel := subscribeNotifications(r, func(line string) (string, bool) {
+		return parseOutputNotification(line, paneID)
+	}, unlink)
+
+	return out, cancel, nil
+}
+
+// Make sure the given window is in the runner's session, so that tmux sends
+// the output of its panes. Returns true if it is there because of this call,
+// and so needs a matching call to unlinkForOutput.
+func (r *Runner) linkForOutput(windowID string) (bool, error) {
+	r.outputLinksMu.Lock()
+	defer r.outputLinksMu.Unlock()
+
+	session := r.ControlSession()
+
+	if r.outputLinks[windowID] > 0 {
+		r.outputLinks[windowID]++
+		return true, nil
+	}
+
+	output, err := r.Run(fmt.Sprintf("list-windows -t %s -F '#{window_id}'", Quote(session)))
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range strings.Split(Trim(output), "\n") {
+		if id == windowID {
+			return false, nil
+		}
+	}
+
+	var cmd string = fmt.Sprintf("link-window -d -s %s -t %s", Quote(windowID), Quote(session+":"))
+	if _, err = r.Run(cmd); err != nil {
+		return false, err
+	}
+
+	if r.outputLinks == nil {
+		r.outputLinks = make(map[string]int)
+	}
+	r.outputLinks[windowID] = 1
+
+	return true, nil
+}
+
+// Undo a call to linkForOutput which returned true, unlinking the window from
+// the runner's session if nothing else needs it there
+func (r *Runner) unlinkForOutput(windowID string) {
+	r.outputLinksMu.Lock()
+	defer r.outputLinksMu.Unlock()
+
+	// After Reconnect, the runner has a new session with nothing linked to it
+	if r.outputLinks[windowID] <= 0 {
+		return
+	}
+
+	r.outputLinks[windowID]--
+	if r.outputLinks[windowID] > 0 {
+		return
+	}
+	delete(r.outputLinks, windowID)
+
+	// The window may have been closed since; there's nothing to undo then
+	_, _ = r.Run(fmt.Sprintf("unlink-window -t %s", Quote(r.ControlSession()+":"+windowID)))
+}
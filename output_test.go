@@ -0,0 +1,52 @@
+package tmux
+
+import "testing"
+
+func TestDecodeOutput(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{"", ""},
+		{"hello", "hello"},
+		{`hi\015\012`, "hi\r\n"},
+		{`\033[1mbold\033[0m`, "\x1b[1mbold\x1b[0m"},
+		{`a\134b`, `a\b`},
+		{`\000`, "\x00"},
+		{`\377`, "\xff"},
+
+		// Not an escape: too short, or not octal digits
+		{`a\01`, `a\01`},
+		{`\089`, `\089`},
+		{`\`, `\`},
+	}
+
+	for _, test := range tests {
+		if actual := decodeOutput(test.in); actual != test.expected {
+			t.Errorf("decodeOutput(%q): expected %q but found %q", test.in, test.expected, actual)
+		}
+	}
+}
+
+func TestParseOutputNotification(t *testing.T) {
+	tests := []struct {
+		line, pane string
+		expected   string
+		ok         bool
+	}{
+		{`%output %1 hi\015\012`, "%1", "hi\r\n", true},
+		{`%output %1 a b`, "%1", "a b", true},
+		{`%output %12 hi`, "%1", "", false},
+		{`%output %2 hi`, "%1", "", false},
+		{`%extended-output %1 25 : hi\015\012`, "%1", "hi\r\n", true},
+		{`%extended-output %2 25 : hi`, "%1", "", false},
+		{`%window-add @1`, "%1", "", false},
+		{`hi`, "%1", "", false},
+	}
+
+	for _, test := range tests {
+		actual, ok := parseOutputNotification(test.line, test.pane)
+		if ok != test.ok || actual != test.expected {
+			t.Errorf("parseOutputNotification(%q, %q): expected %q, %v but found %q, %v", test.line, test.pane, test.expected, test.ok, actual, ok)
+		}
+	}
+}
@@ -1,11 +1,529 @@
 package tmux
 
-import "fmt"
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Set the width of the given pane
 func (r *Runner) SetPaneWidth(pane string, width int) error {
-	var cmd string = fmt.Sprintf("resize-pane -x '%d' -t '%s'", width, pane)
+	var cmd string = fmt.Sprintf("resize-pane -x %d -t %s", width, Quote(pane))
 
 	_, err := r.Run(cmd)
 	return err
 }
+
+// The position and size of a pane, in cells, relative to the top left corner
+// of its window
+type PanePosition struct {
+	// The pane ID, like "%0"
+	Pane string
+
+	// The column of the pane's leftmost cell
+	Left int
+
+	// The row of the pane's topmost cell
+	Top int
+
+	// The width of the pane
+	Width int
+
+	// The height of the pane
+	Height int
+}
+
+// Returns true if the cell at (x, y) is inside the pane. Cells on the borders
+// between panes are not inside any pane.
+func (p PanePosition) Contains(x, y int) bool {
+	return x >= p.Left && x < p.Left+p.Width && y >= p.Top && y < p.Top+p.Height
+}
+
+// Returns the position and size of each pane in the active window
+func (r *Runner) PanePositions() ([]PanePosition, error) {
+	var err error
+
+	var output string
+	var cmd string = "list-panes -F '#{pane_id} #{pane_left} #{pane_top} #{pane_width} #{pane_height}'"
+	if output, err = r.Run(cmd); err != nil {
+		return nil, err
+	}
+
+	positions := make([]PanePosition, 0)
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		tokens := strings.Split(line, " ")
+		if len(tokens) != 5 {
+			return nil, fmt.Errorf("expected line to be a string with five elements separated by spaces but found '%s'", line)
+		}
+
+		values := make([]int, 4)
+		for i, token := range tokens[1:] {
+			if values[i], err = strconv.Atoi(token); err != nil {
+				return nil, fmt.Errorf("error parsing element %d of line '%s': '%s'", i+2, line, err.Error())
+			}
+		}
+
+		positions = append(positions, PanePosition{
+			Pane:   tokens[0],
+			Left:   values[0],
+			Top:    values[1],
+			Width:  values[2],
+			Height: values[3],
+		})
+	}
+
+	return positions, nil
+}
+
+// Returns the ID of the pane in the active window which covers the cell at
+// (x, y), where (0, 0) is the top left corner of the window. Returns
+// [ErrNotFound] if the cell is on a border between panes, or outside the
+// window.
+func (r *Runner) PaneAt(x, y int) (string, error) {
+	var err error
+
+	var positions []PanePosition
+	if positions, err = r.PanePositions(); err != nil {
+		return "", err
+	}
+
+	for _, p := range positions {
+		if p.Contains(x, y) {
+			return p.Pane, nil
+		}
+	}
+
+	return "", ErrNotFound
+}
+
+// Returns the command tmux was told to run when the given pane was created,
+// which may differ from the command running in it now. Returns an empty string
+// if the pane was started with the default shell.
+//
+// tmux quotes the command the way it would be written in a tmux command, so a
+// pane started with "sleep 100" is reported as "\"sleep 100\"". It can be
+// passed back to tmux as part of a command given to [Runner.Run] as it is.
+func (r *Runner) PaneStartCommand(pane string) (string, error) {
+	return r.displayMessage(pane, "#{pane_start_command}")
+}
+
+// Returns the working directory of the command running in the given pane
+func (r *Runner) GetPanePath(target string) (string, error) {
+	return r.displayMessage(target, "#{pane_current_path}")
+}
+
+// Returns the name of the command running in the given pane, like "bash" or
+// "vim"
+func (r *Runner) GetPaneCommand(target string) (string, error) {
+	return r.displayMessage(target, "#{pane_current_command}")
+}
+
+// Set the title of the given pane, which tmux shows in the pane's border when
+// pane-border-status is on; see [Runner.SetPaneBorderStatus]. Programs in the
+// pane can change the title too, with an escape sequence, unless the
+// allow-rename option is off.
+//
+// The title is used exactly as given: tmux expands formats in titles, so any
+// "#" is doubled to stop "#{...}" in it being expanded.
+func (r *Runner) SetPaneTitle(target, title string) error {
+	title = strings.ReplaceAll(title, "#", "##")
+
+	_, err := r.Run(fmt.Sprintf("select-pane -t %s -T %s", Quote(target), Quote(title)))
+	return err
+}
+
+// Set where tmux shows a status line in the border of each pane, with the
+// pane's title by default: "top", "bottom", or "off". This sets the global
+// pane-border-status option, so it applies to every window which doesn't set
+// the option itself; to set it for one window, use [Runner.SetOption].
+func (r *Runner) SetPaneBorderStatus(status string) error {
+	switch status {
+	case "top", "bottom", "off":
+	default:
+		return fmt.Errorf("expected pane border status to be 'top', 'bottom', or 'off' but found '%s'", status)
+	}
+
+	return r.SetOption(OptionScope{Kind: WindowOption, Global: true}, "pane-border-status", status)
+}
+
+// A pane, as returned by [Runner.ListPanes]
+type Pane struct {
+	// The pane ID, like "%0"
+	ID string
+
+	// The pane's index in its window
+	Index int
+
+	// The width of the pane
+	Width int
+
+	// The height of the pane
+	Height int
+
+	// True if this is the active pane in its window
+	Active bool
+
+	// The command running in the pane, like "bash" or "vim"
+	CurrentCommand string
+
+	// The working directory of the command running in the pane
+	CurrentPath string
+
+	// True if the pane is at the top of its window
+	AtTop bool
+}
+
+// Returns the panes in the active window
+func (r *Runner) ListPanes() ([]Pane, error) {
+	var err error
+
+	var output string
+	var format string = joinFields(
+		"#{pane_id}",
+		"#{pane_index}",
+		"#{pane_width}",
+		"#{pane_height}",
+		"#{pane_active}",
+		"#{pane_current_command}",
+		"#{pane_current_path}",
+		"#{pane_at_top}",
+	)
+	if output, err = r.Run(fmt.Sprintf("list-panes -F %s", Quote(format))); err != nil {
+		return nil, err
+	}
+
+	panes := make([]Pane, 0)
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		var fields []string
+		if fields, err = splitFields(line, 8); err != nil {
+			return nil, err
+		}
+
+		p := Pane{
+			ID:             fields[0],
+			Active:         fields[4] == "1",
+			CurrentCommand: fields[5],
+			CurrentPath:    fields[6],
+			AtTop:          fields[7] == "1",
+		}
+
+		if p.Index, err = strconv.Atoi(fields[1]); err != nil {
+			return nil, fmt.Errorf("error parsing pane index of line '%s': '%s'", line, err.Error())
+		}
+		if p.Width, err = strconv.Atoi(fields[2]); err != nil {
+			return nil, fmt.Errorf("error parsing pane width of line '%s': '%s'", line, err.Error())
+		}
+		if p.Height, err = strconv.Atoi(fields[3]); err != nil {
+			return nil, fmt.Errorf("error parsing pane height of line '%s': '%s'", line, err.Error())
+		}
+
+		panes = append(panes, p)
+	}
+
+	return panes, nil
+}
+
+// Split the given pane, or the active pane of the given window, in two, and
+// return the ID of the new pane. If vertical is true, the panes are stacked
+// one above the other, with the new pane below (split-window -v); otherwise
+// they are side by side, with the new pane on the right (split-window -h). If
+// target is empty, the runner's current pane is split.
+func (r *Runner) SplitWindow(target string, vertical bool) (string, error) {
+	var cmd string = "split-window -P -F '#{pane_id}'"
+	if vertical {
+		cmd += " -v"
+	} else {
+		cmd += " -h"
+	}
+	if target != "" {
+		cmd += fmt.Sprintf(" -t %s", Quote(target))
+	}
+
+	output, err := r.Run(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return Trim(output), nil
+}
+
+// Restart the given pane with a new command, run by the shell, killing the
+// command running in it if there is one. The pane keeps its ID and position.
+// If command is empty, the command the pane was started with is run again.
+// Returns the tmux error if there is no such pane.
+func (r *Runner) RespawnPane(target, command string) error {
+	var cmd string = fmt.Sprintf("respawn-pane -k -t %s", Quote(target))
+	if command != "" {
+		cmd += fmt.Sprintf(" %s", Quote(command))
+	}
+
+	_, err := r.Run(cmd)
+	return err
+}
+
+// Move the pane src next to the pane dst, which may be in another window,
+// splitting dst side by side if horizontal is true, or one above the other if
+// not. The moved pane keeps its ID and the program running in it. If src was
+// the only pane in its window, that window is closed; otherwise the window
+// carries on without it. Unless a window is closed, the active pane and window
+// aren't changed.
+func (r *Runner) JoinPane(src, dst string, horizontal bool) error {
+	var cmd string = "join-pane -d"
+	if horizontal {
+		cmd += " -h"
+	} else {
+		cmd += " -v"
+	}
+	cmd += fmt.Sprintf(" -s %s -t %s", Quote(src), Quote(dst))
+
+	_, err := r.Run(cmd)
+	return err
+}
+
+// Returns the ID of the active pane in the window containing the given pane
+func (r *Runner) activePaneOfWindow(pane string) (string, error) {
+	window, err := r.displayMessage(pane, "#{window_id}")
+	if err != nil {
+		return "", err
+	}
+
+	return r.displayMessage(window, "#{pane_id}")
+}
+
+// Swap panes a and b, keeping the focus on the same content. Swapping panes
+// moves their contents, including the commands running in them, and the
+// active pane of each window involved stays the pane it was before the swap,
+// wherever it has moved to: if a was active, b's old position is active
+// afterwards, since that is where a's content now is.
+func (r *Runner) SwapPanePreservingFocus(a, b string) error {
+	var err error
+
+	var activeA, activeB string
+	if activeA, err = r.activePaneOfWindow(a); err != nil {
+		return err
+	}
+	if activeB, err = r.activePaneOfWindow(b); err != nil {
+		return err
+	}
+
+	if _, err = r.Run(fmt.Sprintf("swap-pane -d -s %s -t %s", Quote(a), Quote(b))); err != nil {
+		return err
+	}
+
+	for _, active := range []string{activeA, activeB} {
+		if err = r.SelectPane(active); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the panes in the given window which are sitting idle at a shell
+// prompt, and have been for at least the given duration.
+//
+// This is a heuristic. A pane counts as being at a shell prompt if it was
+// started with the default shell, and the command running in it is that shell,
+// so a pane running a shell started some other way is never reported. The
+// time of the pane's last activity comes from #{pane_activity} where tmux has
+// it, and otherwise from #{window_activity}, which is the last activity of any
+// pane in the window, so in that case a pane is only reported once the whole
+// window has been quiet. tmux records activity times to the second.
+func (r *Runner) IdlePanes(windowID string, idle time.Duration) ([]string, error) {
+	var err error
+
+	var output string
+	var format string = joinFields(
+		"#{pane_id}",
+		"#{pane_current_command}",
+		"#{pane_start_command}",
+		"#{pane_activity}",
+		"#{window_activity}",
+		"#{default-shell}",
+	)
+	if output, err = r.Run(fmt.Sprintf("list-panes -t %s -F %s", Quote(windowID), Quote(format))); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	panes := make([]string, 0)
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		var fields []string
+		if fields, err = splitFields(line, 6); err != nil {
+			return nil, err
+		}
+
+		if fields[2] != "" || fields[1] != path.Base(fields[5]) {
+			continue
+		}
+
+		activity := fields[3]
+		if activity == "" {
+			activity = fields[4]
+		}
+
+		var seconds int64
+		if seconds, err = strconv.ParseInt(activity, 10, 64); err != nil {
+			return nil, fmt.Errorf("error parsing activity time of line '%s': '%s'", line, err.Error())
+		}
+
+		if now.Sub(time.Unix(seconds, 0)) >= idle {
+			panes = append(panes, fields[0])
+		}
+	}
+
+	return panes, nil
+}
+
+// Returns the index of the given pane in its window. Pane IDs, like "%3", are
+// unique across the server and don't change, but indexes, which count from 0
+// (or pane-base-index) in each window, are what users see.
+func (r *Runner) PaneIndex(pane string) (int, error) {
+	output, err := r.displayMessage(pane, "#{pane_index}")
+	if err != nil {
+		return 0, err
+	}
+
+	index, err := strconv.Atoi(output)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing pane index '%s': '%s'", output, err.Error())
+	}
+
+	return index, nil
+}
+
+// Returns the ID of the pane with the given index in the given window.
+// Returns an error wrapping [ErrNotFound] if the window has no such pane.
+func (r *Runner) PaneByIndex(windowID string, index int) (string, error) {
+	var err error
+
+	var output string
+	if output, err = r.Run(fmt.Sprintf("list-panes -t %s -F '#{pane_index} #{pane_id}'", Quote(windowID))); err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		tokens := strings.Split(line, " ")
+		if len(tokens) != 2 {
+			return "", fmt.Errorf("expected line to be a string with two elements separated by spaces but found '%s'", line)
+		}
+
+		if tokens[0] == strconv.Itoa(index) {
+			return tokens[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("no pane with index %d in window '%s': %w", index, windowID, ErrNotFound)
+}
+
+// Make the given pane the active pane in its window
+func (r *Runner) SelectPane(target string) error {
+	_, err := r.Run(fmt.Sprintf("select-pane -t %s", Quote(target)))
+	return err
+}
+
+// Kill the given pane. If it is the last pane in its window, tmux kills the
+// window too, and if that is the last window in its session, the session, all
+// without reporting an error. Returns the tmux error if there is no such pane.
+func (r *Runner) KillPane(target string) error {
+	_, err := r.Run(fmt.Sprintf("kill-pane -t %s", Quote(target)))
+	return err
+}
+
+// Toggle the zoom of the given pane: if its window isn't zoomed, the pane is
+// zoomed to fill the window, and if the window is already zoomed, it is
+// unzoomed. Since this flips whatever the current state is, use
+// [Runner.IsPaneZoomed] first to zoom or unzoom for certain.
+func (r *Runner) ZoomPane(target string) error {
+	_, err := r.Run(fmt.Sprintf("resize-pane -Z -t %s", Quote(target)))
+	return err
+}
+
+// Returns true if the given pane is zoomed to fill its window
+func (r *Runner) IsPaneZoomed(target string) (bool, error) {
+	output, err := r.displayMessage(target, "#{&&:#{window_zoomed_flag},#{pane_active}}")
+	if err != nil {
+		return false, err
+	}
+
+	return output == "1", nil
+}
+
+// Returns the time of the given pane's last activity, in seconds, as reported
+// by tmux: from #{pane_activity} where tmux has it, otherwise from
+// #{window_activity}
+func (r *Runner) paneActivity(pane string) (string, error) {
+	output, err := r.displayMessage(pane, joinFields("#{pane_activity}", "#{window_activity}"))
+	if err != nil {
+		return "", err
+	}
+
+	fields, err := splitFields(output, 2)
+	if err != nil {
+		return "", err
+	}
+
+	if fields[0] != "" {
+		return fields[0], nil
+	}
+	return fields[1], nil
+}
+
+// Returns true if the given pane has had activity since the last call to
+// [Runner.AcknowledgePaneOutput] for it, or if that has never been called for
+// it. This is a cheap check a polling loop can make before capturing the pane.
+//
+// Activity times come from #{pane_activity} where tmux has it, and otherwise
+// from #{window_activity}, in which case activity in any pane of the window
+// counts. tmux records them to the second, so output in the same second as the
+// acknowledgement may be missed until there is more; see
+// [Runner.ResetPaneOutput] to start afresh.
+func (r *Runner) PaneHasNewOutput(pane string) (bool, error) {
+	activity, err := r.paneActivity(pane)
+	if err != nil {
+		return false, err
+	}
+
+	r.stateMu.Lock()
+	baseline, ok := r.paneBaselines[pane]
+	r.stateMu.Unlock()
+
+	return !ok || activity != baseline, nil
+}
+
+// Record that the output of the given pane so far has been seen, so that
+// [Runner.PaneHasNewOutput] returns false until there is more activity
+func (r *Runner) AcknowledgePaneOutput(pane string) error {
+	activity, err := r.paneActivity(pane)
+	if err != nil {
+		return err
+	}
+
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+
+	if r.paneBaselines == nil {
+		r.paneBaselines = make(map[string]string)
+	}
+	r.paneBaselines[pane] = activity
+
+	return nil
+}
+
+// Forget what has been acknowledged for the given pane, so that
+// [Runner.PaneHasNewOutput] returns true for it until it is acknowledged again
+func (r *Runner) ResetPaneOutput(pane string) {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+
+	delete(r.paneBaselines, pane)
+}
@@ -0,0 +1,112 @@
+package tmux
+
+import (
+	"errors"
+	"testing"
+)
+
+// An 80x24 window split into a pane on the left and two on the right, with a
+// border in column 40 and another in row 11 on the right:
+//
+//	%0: columns 0-39,  rows 0-23
+//	%1: columns 41-79, rows 0-10
+//	%2: columns 41-79, rows 12-23
+var testPanePositions = []PanePosition{
+	{"%0", 0, 0, 40, 24},
+	{"%1", 41, 0, 39, 11},
+	{"%2", 41, 12, 39, 12},
+}
+
+// Returns the pane which contains (x, y), as PaneAt does, or "" if none does
+func testPaneAt(x, y int) string {
+	for _, p := range testPanePositions {
+		if p.Contains(x, y) {
+			return p.Pane
+		}
+	}
+	return ""
+}
+
+func TestPanePositionContains(t *testing.T) {
+	tests := []struct {
+		x, y     int
+		expected string
+	}{
+		// Corners of each pane
+		{0, 0, "%0"},
+		{39, 0, "%0"},
+		{0, 23, "%0"},
+		{39, 23, "%0"},
+		{41, 0, "%1"},
+		{79, 10, "%1"},
+		{41, 12, "%2"},
+		{79, 23, "%2"},
+
+		// The borders between panes
+		{40, 0, ""},
+		{40, 23, ""},
+		{41, 11, ""},
+		{79, 11, ""},
+
+		// Outside the window
+		{-1, 0, ""},
+		{0, -1, ""},
+		{80, 0, ""},
+		{0, 24, ""},
+		{79, 24, ""},
+	}
+
+	for _, test := range tests {
+		if actual := testPaneAt(test.x, test.y); actual != test.expected {
+			t.Errorf("(%d, %d): expected pane %q but found %q", test.x, test.y, test.expected, actual)
+		}
+	}
+}
+
+func TestPaneAt(t *testing.T) {
+	r := newTestRunner(t, Config{})
+
+	if _, err := r.Run("split-window -h -d"); err != nil {
+		t.Fatalf("split-window returned error: %v", err)
+	}
+
+	positions, err := r.PanePositions()
+	if err != nil {
+		t.Fatalf("PanePositions returned error: %v", err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 panes but found %v", positions)
+	}
+
+	left, right := positions[0], positions[1]
+	if left.Left > right.Left {
+		left, right = right, left
+	}
+	border := left.Left + left.Width
+
+	tests := []struct {
+		x, y     int
+		expected string
+	}{
+		{0, 0, left.Pane},
+		{border - 1, left.Height - 1, left.Pane},
+		{border + 1, 0, right.Pane},
+		{right.Left + right.Width - 1, right.Height - 1, right.Pane},
+		{border, 0, ""},
+		{right.Left + right.Width, 0, ""},
+		{0, left.Height, ""},
+	}
+
+	for _, test := range tests {
+		pane, err := r.PaneAt(test.x, test.y)
+		if test.expected == "" {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("(%d, %d): expected ErrNotFound but found %q, %v", test.x, test.y, pane, err)
+			}
+			continue
+		}
+		if err != nil || pane != test.expected {
+			t.Errorf("(%d, %d): expected pane %q but found %q, %v", test.x, test.y, test.expected, pane, err)
+		}
+	}
+}
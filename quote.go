@@ -0,0 +1,51 @@
+package tmux
+
+import "strings"
+
+// Returns true if c can appear in an argument to a tmux command without being
+// quoted
+func isSafeArgumentChar(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	default:
+		return strings.ContainsRune("-_./:@%+,", c)
+	}
+}
+
+// Quote s so that tmux reads it as a single argument, exactly as given, when
+// it appears in a command sent to a [Runner]. Strings made up only of letters,
+// digits, and a few punctuation characters like "-", ":", and "@" are returned
+// unchanged; anything else is put in single quotes, so that spaces, ";", "$",
+// "~", "#", and backslashes lose their special meaning. Single quotes and
+// newlines can't appear inside single quotes, so they're written outside them
+// as "\'", "\n", or "\r", which tmux joins to the quoted parts around them.
+//
+// Quoting only affects how tmux splits the command into arguments; it doesn't
+// stop tmux from expanding formats like "#{session_name}" in arguments which
+// take formats.
+func Quote(s string) string {
+	// tmux can read a word starting with "%" as something other than an
+	// argument, so those are always quoted
+	if s != "" && s[0] != '%' && strings.IndexFunc(s, func(c rune) bool { return !isSafeArgumentChar(c) }) < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, c := range s {
+		switch c {
+		case '\'':
+			b.WriteString(`'\''`)
+		case '\n':
+			b.WriteString(`'\n'`)
+		case '\r':
+			b.WriteString(`'\r'`)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	b.WriteByte('\'')
+
+	return b.String()
+}
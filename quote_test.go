@@ -0,0 +1,32 @@
+package tmux
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{"work", "work"},
+		{"my-session_2", "my-session_2"},
+		{"work:1.2", "work:1.2"},
+		{"@1", "@1"},
+		{"", "''"},
+		{"%1", "'%1'"},
+		{"my session", "'my session'"},
+		{"a;b", "'a;b'"},
+		{"$HOME", "'$HOME'"},
+		{"~", "'~'"},
+		{"#{pane_id}", "'#{pane_id}'"},
+		{`a\b`, `'a\b'`},
+		{"it's", `'it'\''s'`},
+		{"a\nb", `'a'\n'b'`},
+		{"a\r\nb", `'a'\r''\n'b'`},
+		{"café", "'café'"},
+	}
+
+	for _, test := range tests {
+		if actual := Quote(test.in); actual != test.expected {
+			t.Errorf("Quote(%q): expected %s but found %s", test.in, test.expected, actual)
+		}
+	}
+}
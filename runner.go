@@ -8,22 +8,29 @@ package tmux
 
 import (
 	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 // A Runner can be used to run tmux commands and read their output, with better
 // performance than [Command]. A Runner starts a "tmux -C" process, and writes
 // to it to send commands, then reads their output.
 //
-// To initialize, use something like this:
+// To create one, use something like this:
 //
-//	var r *tmux.Runner = &tmux.Runner{}
-//
-//	if err = r.Init(); err != nil {
+//	r, err := tmux.NewRunner(tmux.Config{})
+//	if err != nil {
 //		return err
 //	}
 //
@@ -39,27 +46,146 @@ import (
 //
 // The Runner type also has many other functions for tasks like starting a new
 // tmux session, getting the active window, etc.
+//
+// A Runner may be used from several goroutines at once. Commands are sent to
+// tmux one at a time, each waiting for the one before to finish, so a slow
+// command holds up the others.
 type Runner struct {
-	Config      Config
+	Config Config
+
+	// If set, called with each control-mode notification line, like
+	// "%window-add @1", which tmux sends outside the output of a command. Set
+	// this before calling Init. It is called from the goroutine which reads
+	// from tmux, as soon as the notification arrives, so it must not call
+	// methods of the Runner, which would wait for that goroutine forever, and
+	// should return quickly.
+	OnNotification func(line string)
+
+	// Like OnNotification, but called with the notification as parsed by
+	// [ParseNotification], after OnNotification. The same restrictions apply.
+	OnParsedNotification func(n Notification)
+
 	writePipe   io.WriteCloser
 	readPipe    io.ReadCloser
-	readScanner bufio.Scanner
 	tmpSession  string
 	tmuxCommand *exec.Cmd
+
+	// The output of commands read from the "tmux -C" process by scanLines,
+	// including the %begin and %end lines
+	lines chan string
+
+	// The error which stopped scanLines, if any; only valid once lines is
+	// closed
+	readErr *error
+
+	// Closed by Close to stop scanLines
+	done chan struct{}
+
+	// Held while a command is sent and its output read, so that commands run
+	// from different goroutines don't interleave, and while reconnecting
+	mu sync.Mutex
+
+	// Incremented by Reconnect, so that goroutines which find the connection
+	// lost at the same time only reconnect once
+	generation int
+
+	// The number of command responses which are still to come, but which no
+	// caller is waiting for any more, because their context was done
+	skip int
+
+	// Set by Close
+	closed bool
+
+	// When the last command finished, for Config.MinCommandInterval
+	lastCommandDone time.Time
+
+	// Guards features, paneContents, and paneBaselines
+	stateMu sync.Mutex
+
+	// Cached by ServerFeatures
+	features map[string]bool
+
+	// The last text seen by PaneDelta for each pane
+	paneContents map[string]string
+
+	// The activity time of each pane at its last AcknowledgePaneOutput
+	paneBaselines map[string]string
+
+	// The number of SubscribeOutput subscriptions which need each window
+	// linked into the runner's session
+	outputLinks   map[string]int
+	outputLinksMu sync.Mutex
+
+	// Functions called with each notification, by ID
+	listeners    map[int]func(string)
+	nextListener int
+	listenersMu  sync.Mutex
+
+	// The panes paused by flow control
+	paused   map[string]bool
+	pausedMu sync.Mutex
 }
 
-func (r *Runner) readNextLine() (string, error) {
-	b := r.readScanner.Scan()
-	if !b {
-		err := r.readScanner.Err()
-		if err != nil {
-			return "", err
-		} else {
-			return "", io.EOF
+// Reads lines from the "tmux -C" process until its output ends or done is
+// closed. The output of commands is sent to lines, and notifications are
+// passed to dispatchNotification. When the output ends, lines is closed, after
+// setting readErr to the error which ended it, if any. These all belong to one
+// connection, so that after Reconnect, this can finish without touching the
+// new connection's.
+func (r *Runner) scanLines(scanner *bufio.Scanner, lines chan<- string, done <-chan struct{}, readErr *error) {
+	defer close(lines)
+
+	inOutput := false
+	var endLine, errorLine string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if r.Config.Logger != nil {
+			r.Config.Logger.Printf("< %s", line)
+		}
+
+		if !inOutput {
+			if r.isNotificationLine(line) {
+				r.dispatchNotification(line)
+				continue
+			}
+
+			if r.isBeginLine(line) {
+				inOutput = true
+				endLine = r.getExpectedEndLine(line)
+				errorLine = r.getExpectedErrorLine(line)
+			}
+		} else if line == endLine || line == errorLine {
+			inOutput = false
+		}
+
+		select {
+		case lines <- line:
+		case <-done:
+			return
 		}
 	}
 
-	return r.readScanner.Text(), nil
+	*readErr = scanner.Err()
+}
+
+func (r *Runner) readNextLine(ctx context.Context) (string, error) {
+	select {
+	case line, ok := <-r.lines:
+		if !ok {
+			// The reader has stopped for good, whether tmux went away or sent
+			// a line too long to read, so either way the runner needs to
+			// reconnect
+			if *r.readErr != nil {
+				return "", fmt.Errorf("%w: %s", ErrConnectionLost, (*r.readErr).Error())
+			}
+			return "", ErrConnectionLost
+		}
+		return line, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
 }
 
 const tmuxBeginMarker = "%begin"
@@ -75,6 +201,13 @@ func (r *Runner) isBeginLine(line string) bool {
 	return line[:beginMarkerLength] == tmuxBeginMarker
 }
 
+// Notifications are lines starting with "%" outside the output of a command.
+// Lines inside the output of a command can also start with "%", like pane IDs,
+// so this must only be used between commands.
+func (r *Runner) isNotificationLine(line string) bool {
+	return strings.HasPrefix(line, "%") && !r.isBeginLine(line)
+}
+
 func (r *Runner) getExpectedEndLine(beginLine string) string {
 	return fmt.Sprintf("%s %s", tmuxEndMarker, beginLine[len(tmuxBeginMarker)+1:])
 }
@@ -92,9 +225,15 @@ const (
 	stateEnd          readState = 3
 )
 
-func (r *Runner) readCommandOutput() (string, error) {
+// Read the output of the next command. If ctx is done before the output has
+// been read, returns ctx.Err(), and arranges for the rest of the output to be
+// discarded by the next call.
+func (r *Runner) readCommandOutput(ctx context.Context) (string, error) {
 	done := false
 
+	// True while reading the output of a command whose caller has given up on it
+	skipping := false
+
 	var expectedEndLine string
 	var expectedErrorLine string
 
@@ -111,27 +250,43 @@ func (r *Runner) readCommandOutput() (string, error) {
 	for !done {
 		switch state {
 		case stateBeforeOutput:
-			line, err := r.readNextLine()
+			line, err := r.readNextLine(ctx)
 			if err != nil {
+				if ctx.Err() != nil {
+					r.skip++
+				}
 				return "", err
 			}
 
 			if r.isBeginLine(line) {
 				state = stateOutput
-			}
+				skipping = r.skip > 0
 
-			expectedEndLine = r.getExpectedEndLine(line)
-			expectedErrorLine = r.getExpectedErrorLine(line)
+				expectedEndLine = r.getExpectedEndLine(line)
+				expectedErrorLine = r.getExpectedErrorLine(line)
+			}
 		case stateOutput:
-			line, err := r.readNextLine()
+			line, err := r.readNextLine(ctx)
 			if err != nil {
+				// If ctx is done, the rest of this block will be passed over
+				// while the next call waits for a %begin line, so it doesn't
+				// need counting in r.skip. If this was a skipped block, the block
+				// for this command is still to come and needs counting instead,
+				// which leaves r.skip unchanged either way.
 				return "", err
 			}
 
-			if line == expectedEndLine {
-				state = stateEnd
-			} else if line == expectedErrorLine {
-				state = stateError
+			if line == expectedEndLine || line == expectedErrorLine {
+				if skipping {
+					r.skip--
+					skipping = false
+					outputLines = outputLines[:0]
+					state = stateBeforeOutput
+				} else if line == expectedEndLine {
+					state = stateEnd
+				} else {
+					state = stateError
+				}
 			} else {
 				outputLines = append(outputLines, line)
 			}
@@ -144,12 +299,9 @@ func (r *Runner) readCommandOutput() (string, error) {
 		case stateError:
 			result = returnval{
 				output: "",
-				err: fmt.Errorf(
-					fmt.Sprintf(
-						"tmux error: %s",
-						strings.Join(outputLines, "\n"),
-					),
-				),
+				err: &CommandError{
+					Message: strings.Join(outputLines, "\n"),
+				},
 			}
 			done = true
 		}
@@ -158,61 +310,176 @@ func (r *Runner) readCommandOutput() (string, error) {
 	return result.output, result.err
 }
 
-// Before the tmux -C process used by the runner has started, use this to get
-// the list of session names
-func (r *Runner) getSessionNamesByCommand() ([]string, error) {
-	var err error
+// The longest part of a command shortCommand keeps
+const shortCommandLength = 80
 
-	var output []byte
-	if output, err = Command(r.Config, "list-sessions", "-F", "#{session_name}"); err != nil {
-		return nil, err
+// Returns cmd, or its start if it is long, like the keys sent to a pane, to
+// put in an error message
+func shortCommand(cmd string) string {
+	if len(cmd) <= shortCommandLength {
+		return cmd
+	}
+
+	// Cut at the start of a character, rather than in the middle of one
+	end := shortCommandLength
+	for end > 0 && !utf8.RuneStart(cmd[end]) {
+		end--
 	}
 
-	sessionNames := strings.Split(string(output), "\n")
-	return sessionNames, nil
+	return cmd[:end] + "..."
 }
 
-// After the tmux -C process used by the runner has already started, use this to
-// get the list of session names without spawning a new process
-func (r *Runner) getSessionNames() ([]string, error) {
-	var err error
-	var output string
+// Returns a name for the runner's control session which won't clash with any
+// other session, like "tmux-runner-1a2b3c4d5e6f7a8b"
+func newControlSessionName() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
 
-	if output, err = r.Run("list-sessions -F '#{session_name}'"); err != nil {
-		return nil, err
+	return fmt.Sprintf("tmux-runner-%s", hex.EncodeToString(b)), nil
+}
+
+// The default for [Config.MaxLineLength]
+const DefaultMaxLineLength = 16 * 1024 * 1024
+
+// Returns a scanner which reads the lines from r, and fails on one longer than
+// maxLineLength bytes, or than DefaultMaxLineLength if maxLineLength isn't
+// positive
+func newLineScanner(r io.Reader, maxLineLength int) *bufio.Scanner {
+	if maxLineLength <= 0 {
+		maxLineLength = DefaultMaxLineLength
+	}
+
+	// The scanner allows tokens up to the larger of the initial buffer's
+	// capacity and the maximum, so the buffer mustn't start out bigger
+	initial := 4096
+	if maxLineLength < initial {
+		initial = maxLineLength
 	}
 
-	sessionNames := strings.Split(Trim(output), "\n")
-	return sessionNames, nil
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, initial), maxLineLength)
+	return scanner
 }
 
 type Config struct {
 	Socket string
+
+	// The tmux configuration file to use, passed to tmux with "-f". It is only
+	// read when the tmux server starts, so it has no effect if the server is
+	// already running. If empty, tmux uses its default configuration files.
+	ConfigFile string
+
+	// The path to the tmux executable. If empty, tmux is found in PATH.
+	BinaryPath string
+
+	// The longest line of output, in bytes, which a Runner can read. If zero,
+	// DefaultMaxLineLength is used. A longer line stops the runner reading
+	// from tmux, so the command whose output has it, and every command after
+	// it, fails with an error wrapping [ErrConnectionLost] until the runner
+	// reconnects, as it does by itself if AutoReconnect is set.
+	MaxLineLength int
+
+	// The shortest time to leave between one command finishing and the next
+	// being sent. If a command is run sooner than this after the last one
+	// finished, it waits until the interval has passed. This trades the speed
+	// of a tight loop of commands for keeping the tmux server responsive to
+	// its other clients. If zero, commands are sent as soon as they are run.
+	MinCommandInterval time.Duration
+
+	// The keys [Runner.GracefulKillSession] sends to each pane to ask the
+	// program in it to exit. If nil, DefaultExitKeys is used.
+	ExitKeys []string
+
+	// The name of the session a [Runner] creates for its "tmux -C" client. If
+	// empty, a unique name like "tmux-runner-1a2b3c4d5e6f7a8b" is used. Init
+	// fails if a session with this name already exists. tmux replaces "." and
+	// ":" in session names with "_"; [Runner.ControlSession] returns the name
+	// as tmux has it.
+	ControlSessionName string
+
+	// If set, the name of an existing session for the [Runner]'s "tmux -C"
+	// client to attach to, instead of creating a session of its own. Init
+	// fails if there is no such session, and Close and Reconnect leave it
+	// running. ControlSessionName is ignored.
+	//
+	// The runner uses the session as its own: for example,
+	// [Runner.SubscribeOutput] links windows from other sessions into it
+	// while they're being followed.
+	ExistingSession string
+
+	// If set, every command a [Runner] sends to tmux is logged here, prefixed
+	// with "> ", and every line it reads from tmux, including the %begin and
+	// %end lines around the output of each command and notifications,
+	// prefixed with "< ". Lines are logged from the goroutine which reads from
+	// tmux, as they arrive.
+	Logger *log.Logger
+
+	// The longest a [Runner] waits for the output of a command run without a
+	// context, as by [Runner.Run] and the methods built on it, before giving
+	// up and returning context.DeadlineExceeded. If zero, it waits as long as
+	// it takes. To give a single command a different deadline, use
+	// [Runner.RunContext], whose context takes the place of this timeout.
+	CommandTimeout time.Duration
+
+	// If true, when [Runner.Run] or [Runner.RunBatch] finds that the
+	// "tmux -C" process has gone away, it calls [Runner.Reconnect] and tries
+	// the command once more, rather than returning [ErrConnectionLost]. A
+	// command which tmux had already run before the connection was lost may
+	// then run twice.
+	AutoReconnect bool
+}
+
+// Create a Runner and initialize it with [Runner.Init], ready to use. If Init
+// fails, anything it started is cleaned up, and nil is returned with the
+// error. Close the Runner when you're done with it.
+func NewRunner(c Config) (*Runner, error) {
+	r := &Runner{}
+	if err := r.Init(c); err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	return r, nil
 }
 
-// Run this before attempting to use the Runner. This starts a "tmux -C" process
-// and a tmux session which it uses to run commands; make sure to call Close()
-// to dispose of these resources
+// Run this before attempting to use a Runner not created by [NewRunner]. This
+// starts a "tmux -C" process and a tmux session which it uses to run commands,
+// with a unique name like "tmux-runner-1a2b3c4d5e6f7a8b"; make sure to call
+// Close() to dispose of these resources. If no tmux server is running, one is
+// started.
 func (r *Runner) Init(c Config) error {
+	r.Config = c
+
+	return r.start()
+}
+
+// Start the "tmux -C" process and its session, for Init and Reconnect
+func (r *Runner) start() error {
 	var err error
 
-	r.Config = c
+	c := r.Config
 
 	var tmuxPath string
-	if tmuxPath, err = Tmux(); err != nil {
+	if tmuxPath, err = tmuxBinary(c); err != nil {
 		return err
 	}
 
-	var sessionsBeforeStart []string
-	if sessionsBeforeStart, err = r.getSessionNamesByCommand(); err != nil {
-		return err
+	sessionName := c.ExistingSession
+	if sessionName == "" {
+		if sessionName = c.ControlSessionName; sessionName == "" {
+			if sessionName, err = newControlSessionName(); err != nil {
+				return err
+			}
+		}
 	}
 
-	if c.Socket != "" {
-		r.tmuxCommand = exec.Command(tmuxPath, "-L", c.Socket, "-C")
-	} else {
-		r.tmuxCommand = exec.Command(tmuxPath, "-C")
+	var args []string
+	if args, err = controlArgs(c, sessionName); err != nil {
+		return err
 	}
+	r.tmuxCommand = exec.Command(tmuxPath, args...)
 
 	writePipe, err := r.tmuxCommand.StdinPipe()
 	if err != nil {
@@ -226,59 +493,264 @@ func (r *Runner) Init(c Config) error {
 	}
 	r.readPipe = readPipe
 
-	r.readScanner = *bufio.NewScanner(readPipe)
+	scanner := newLineScanner(readPipe, c.MaxLineLength)
+
+	// tmux may not give the session the name it was asked to, for example
+	// replacing "." and ":" with "_", so the runner goes by the name in the
+	// %session-changed notification, which says which session the client is in
+	sessionChanged := make(chan string, 1)
+	remove := r.addNotificationListener(func(line string) {
+		if n, ok := ParseNotification(line); ok && n.Name == "session-changed" {
+			select {
+			case sessionChanged <- n.Text:
+			default:
+			}
+		}
+	})
+	defer remove()
+
+	if err = r.tmuxCommand.Start(); err != nil {
+		return err
+	}
 
-	r.tmuxCommand.Start()
+	r.lines = make(chan string)
+	r.done = make(chan struct{})
+	r.readErr = new(error)
+	go r.scanLines(scanner, r.lines, r.done, r.readErr)
 
-	// When tmux -C first runs, it prints a pair of %begin and %end lines with
-	// nothing in between
-	_, err = r.readCommandOutput()
+	// When tmux -C first runs, it prints the output of the new-session or
+	// attach-session command: a pair of %begin and %end lines with nothing in
+	// between, or the error if there's no session to attach to
+	_, err = r.readCommandOutput(context.Background())
 	if err != nil {
 		return err
 	}
 
-	var sessionsAfterStart []string
-	if sessionsAfterStart, err = r.getSessionNames(); err != nil {
+	// From here on, a session created for the runner exists, so if anything
+	// fails it is killed rather than left behind
+	fail := func(err error) error {
+		if c.ExistingSession == "" {
+			select {
+			case name := <-sessionChanged:
+				sessionName = name
+			default:
+			}
+			_, _ = Command(c, "kill-session", "-t", "="+sessionName)
+		}
 		return err
 	}
 
-	sessionMap := make(map[string]bool)
-	for _, session := range sessionsBeforeStart {
-		sessionMap[session] = true
+	// The notification comes before the output of any later command, so once
+	// this one's output has been read, it has been seen if it was sent
+	if err = r.writeCommands("display-message -p ''\n"); err != nil {
+		return fail(err)
+	}
+	if _, err = r.readCommandOutput(context.Background()); err != nil {
+		return fail(err)
+	}
+
+	select {
+	case name := <-sessionChanged:
+		sessionName = name
+	default:
 	}
 
-	newSessions := make([]string, 0)
-	for _, session := range sessionsAfterStart {
-		if !sessionMap[session] {
-			newSessions = append(newSessions, session)
+	r.tmpSession = sessionName
+
+	return nil
+}
+
+// Returns the arguments to start "tmux -C" with, in the session with the given
+// name: attaching to it if it is Config.ExistingSession, otherwise creating it
+func controlArgs(c Config, sessionName string) ([]string, error) {
+	args, err := globalArgs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ExistingSession != "" {
+		// "=" so that the name isn't matched as a prefix of another session's
+		return append(args, "-C", "attach-session", "-t", "="+sessionName), nil
+	}
+
+	// Create the session with a name of our own, rather than leaving tmux to
+	// pick one, so there's no doubt which session is ours even if other
+	// sessions are being created at the same time
+	return append(args, "-C", "new-session", "-s", sessionName), nil
+}
+
+// Write all of s to w, calling Write as many times as it takes. Returns
+// io.ErrShortWrite if w stops accepting bytes without reporting an error.
+func writeAll(w io.Writer, s string) error {
+	b := []byte(s)
+	for len(b) > 0 {
+		n, err := w.Write(b)
+		if err != nil {
+			return err
 		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		b = b[n:]
 	}
 
-	if len(newSessions) != 1 {
-		return fmt.Errorf("expected exactly 1 new session but found %d: %s", len(newSessions), strings.Join(newSessions, ","))
+	return nil
+}
+
+// Send commands, each terminated by a newline, to the "tmux -C" process. A
+// command written only in part would leave tmux waiting for the rest of it, and
+// the output of every later command out of step, so a failure is treated as
+// losing the connection.
+func (r *Runner) writeCommands(s string) error {
+	if r.Config.Logger != nil {
+		for _, cmd := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+			r.Config.Logger.Printf("> %s", cmd)
+		}
 	}
 
-	r.tmpSession = newSessions[0]
+	if err := writeAll(r.writePipe, s); err != nil {
+		return fmt.Errorf("%w: %s", ErrConnectionLost, err.Error())
+	}
 
 	return nil
 }
 
+// Returns the name of the session the runner created for its "tmux -C" client,
+// from Config.ControlSessionName or chosen by Init, or the one it attached to
+// from Config.ExistingSession, or an empty string if the runner hasn't been
+// initialized. A program which is killed before it can
+// call Close leaves this session behind, so knowing the name lets it be
+// cleaned up later.
+func (r *Runner) ControlSession() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tmpSession
+}
+
+// Returns the runner's control session if the runner created it, and so kills
+// it on Close, or an empty string if it attached to Config.ExistingSession,
+// which is treated like any other session
+func (r *Runner) ownSession() string {
+	if r.Config.ExistingSession != "" {
+		return ""
+	}
+
+	return r.ControlSession()
+}
+
+// Wait until Config.MinCommandInterval has passed since the last command
+// finished, or until ctx is done, in which case ctx.Err() is returned
+func (r *Runner) throttle(ctx context.Context) error {
+	wait := r.Config.MinCommandInterval - time.Since(r.lastCommandDone)
+	if r.Config.MinCommandInterval <= 0 || wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Record that a command has finished, for Config.MinCommandInterval
+func (r *Runner) markCommandDone() {
+	r.lastCommandDone = time.Now()
+}
+
 // Run a tmux command and return its output. The output will generally have a
-// trailing newline; if this is undesirable, use [Trim].
+// trailing newline; if this is undesirable, use [Trim]. If
+// Config.CommandTimeout is set, gives up waiting for the output after that
+// long, returning context.DeadlineExceeded.
 func (r *Runner) Run(cmd string) (string, error) {
-	cmdBuf := []byte(fmt.Sprintf("%s\n", cmd))
-	bytesWritten, err := r.writePipe.Write(cmdBuf)
-	if err != nil {
+	ctx, cancel := r.commandContext()
+	defer cancel()
+
+	return r.RunContext(ctx, cmd)
+}
+
+// Returns the context for a command run without one: one with
+// Config.CommandTimeout as its timeout, if that is set
+func (r *Runner) commandContext() (context.Context, context.CancelFunc) {
+	if r.Config.CommandTimeout > 0 {
+		return context.WithTimeout(context.Background(), r.Config.CommandTimeout)
+	}
+
+	return context.WithCancel(context.Background())
+}
+
+// Run a tmux command given as separate arguments, like
+// r.RunArgs("list-sessions", "-F", "#{session_name}"), and return its output.
+// Each argument is quoted, so it reaches tmux as a single argument even if it
+// contains spaces or quotes.
+func (r *Runner) RunArgs(args ...string) (string, error) {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = Quote(arg)
+	}
+
+	return r.Run(strings.Join(quoted, " "))
+}
+
+// Like [Runner.Run], but gives up waiting for the command's output if ctx is
+// done first, returning ctx.Err(). The command may still run; its output is
+// discarded when it arrives. Config.CommandTimeout doesn't apply; ctx alone
+// decides how long to wait, whether its deadline is sooner or later.
+func (r *Runner) RunContext(ctx context.Context, cmd string) (string, error) {
+	output, generation, err := r.runContext(ctx, cmd)
+	if err != nil && r.Config.AutoReconnect && errors.Is(err, ErrConnectionLost) {
+		if err = r.reconnect(generation); err != nil {
+			return "", err
+		}
+		output, _, err = r.runContext(ctx, cmd)
+	}
+
+	return output, err
+}
+
+// Run a command, and return its output along with the generation of the
+// connection it was run on, for reconnect
+func (r *Runner) runContext(ctx context.Context, cmd string) (string, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	output, err := r.runLocked(ctx, cmd)
+	return output, r.generation, err
+}
+
+// Run a command, with r.mu held
+func (r *Runner) runLocked(ctx context.Context, cmd string) (string, error) {
+	if err := ctx.Err(); err != nil {
 		return "", err
 	}
 
-	if bytesWritten != len(cmd)+1 {
-		fmt.Printf("Expected to write %d bytes but wrote %d", len(cmd)+1, bytesWritten)
+	if err := r.throttle(ctx); err != nil {
+		return "", err
 	}
+	defer r.markCommandDone()
 
-	var output string
-	if output, err = r.readCommandOutput(); err != nil {
-		return "", fmt.Errorf(fmt.Sprintf("Error running command '%s': '%s", cmd, err.Error()))
+	if err := r.writeCommands(fmt.Sprintf("%s\n", cmd)); err != nil {
+		return "", err
+	}
+
+	output, err := r.readCommandOutput(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", err
+		}
+
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) {
+			cmdErr.Command = cmd
+			return "", cmdErr
+		}
+
+		return "", fmt.Errorf("Error running command '%s': '%w", shortCommand(cmd), err)
 	}
 
 	return output, nil
@@ -286,18 +758,148 @@ func (r *Runner) Run(cmd string) (string, error) {
 
 // Close the test runner. Kills the "tmux -C" session, and closes the temporary
 // tmux session created by Init().
+//
+// It is safe to call Close more than once, and on a Runner whose Init failed
+// or was never called; in those cases it cleans up whatever Init got as far as
+// starting, and otherwise does nothing.
 func (r *Runner) Close() error {
+	r.mu.Lock()
+	if r.closed || r.tmuxCommand == nil || r.tmuxCommand.Process == nil {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	session := r.tmpSession
+	process := r.tmuxCommand.Process
+	done := r.done
+	r.mu.Unlock()
+
 	defer func() {
-		e := r.tmuxCommand.Process.Kill()
+		e := process.Kill()
 		if e != nil {
 			os.Stderr.Write([]byte(fmt.Sprintf("Error killing tmux -C process: '%s'", e.Error())))
 		}
+		if done != nil {
+			close(done)
+		}
 	}()
 
-	var err error
-	if _, err = r.Run(fmt.Sprintf("kill-session -t %s", r.tmpSession)); err != nil {
+	if session == "" || r.Config.ExistingSession != "" {
+		return nil
+	}
+
+	return r.KillSession(session)
+}
+
+// Stop the "tmux -C" process, if it is still running, and start a new one, as
+// Init does, with the same Config. Use this after a command returns
+// [ErrConnectionLost]. If the tmux server outlived the old process, the old
+// control session is killed as well.
+func (r *Runner) Reconnect() error {
+	return r.reconnect(-1)
+}
+
+// Reconnect, unless another goroutine already has since the given generation
+// of the connection, or always if generation is -1
+func (r *Runner) reconnect(generation int) error {
+	r.mu.Lock()
+
+	if r.closed {
+		r.mu.Unlock()
+		return errors.New("cannot reconnect a closed runner")
+	}
+
+	if generation >= 0 && generation != r.generation {
+		r.mu.Unlock()
+		return nil
+	}
+
+	oldSession := r.tmpSession
+
+	if r.tmuxCommand != nil && r.tmuxCommand.Process != nil {
+		// The process may well have exited already, so errors from killing it
+		// and waiting for it are expected
+		_ = r.tmuxCommand.Process.Kill()
+		if r.done != nil {
+			close(r.done)
+		}
+		_ = r.tmuxCommand.Wait()
+	}
+
+	// Cleared so that if start fails before replacing them, the old process
+	// isn't torn down again by the next Reconnect or by Close
+	r.tmuxCommand = nil
+	r.done = nil
+
+	r.tmpSession = ""
+	r.skip = 0
+	r.generation++
+
+	r.stateMu.Lock()
+	r.features = nil
+	r.stateMu.Unlock()
+
+	r.outputLinksMu.Lock()
+	r.outputLinks = nil
+	r.outputLinksMu.Unlock()
+
+	r.pausedMu.Lock()
+	r.paused = nil
+	r.pausedMu.Unlock()
+
+	// A session named in the config has to go before the new one can take
+	// its name; otherwise it's killed once the new one is running, so that
+	// the server doesn't exit in between if it's the only session
+	if oldSession != "" && r.Config.ControlSessionName != "" && r.Config.ExistingSession == "" {
+		_, _ = Command(r.Config, "kill-session", "-t", "="+oldSession)
+		oldSession = ""
+	}
+
+	err := r.start()
+	r.mu.Unlock()
+	if err != nil {
 		return err
 	}
 
-	return err
+	if oldSession != "" && r.Config.ExistingSession == "" {
+		if has, err := r.HasSession(oldSession); err == nil && has {
+			return r.KillSession(oldSession)
+		}
+	}
+
+	return nil
+}
+
+// How long Ping waits for tmux to answer
+const pingTimeout = 5 * time.Second
+
+// Check that the runner can still run commands, by running a trivial one.
+// Returns an error if it fails, or if tmux takes longer than five seconds to
+// answer. After an error wrapping [ErrConnectionLost], [Runner.Reconnect] may
+// help.
+func (r *Runner) Ping() error {
+	r.mu.Lock()
+	closed, initialized := r.closed, r.writePipe != nil
+	r.mu.Unlock()
+
+	if closed {
+		return errors.New("runner is closed")
+	}
+	if !initialized {
+		return errors.New("runner is not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	output, err := r.RunContext(ctx, "display-message -p ok")
+	if err != nil {
+		return err
+	}
+
+	if Trim(output) != "ok" {
+		return fmt.Errorf("unexpected reply to ping: '%s'", output)
+	}
+
+	return nil
 }
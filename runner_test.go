@@ -0,0 +1,472 @@
+package tmux
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// A writer which accepts at most limit bytes per call to Write
+type shortWriter struct {
+	limit int
+	calls int
+	buf   strings.Builder
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	w.calls++
+	if len(p) > w.limit {
+		p = p[:w.limit]
+	}
+	return w.buf.Write(p)
+}
+
+// A writer which accepts nothing, without reporting an error
+type stuckWriter struct{}
+
+func (stuckWriter) Write(p []byte) (int, error) {
+	return 0, nil
+}
+
+// A writer which fails after accepting limit bytes
+type failingWriter struct {
+	limit int
+	buf   strings.Builder
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	remaining := w.limit - w.buf.Len()
+	if remaining <= 0 {
+		return 0, errWriteFailed
+	}
+	if len(p) > remaining {
+		p = p[:remaining]
+	}
+	return w.buf.Write(p)
+}
+
+func TestWriteAllShortWrites(t *testing.T) {
+	s := "list-sessions -F '#{session_name}'\nlist-windows\n"
+
+	w := &shortWriter{limit: 3}
+	if err := writeAll(w, s); err != nil {
+		t.Fatalf("writeAll returned error: %v", err)
+	}
+
+	if w.buf.String() != s {
+		t.Errorf("expected %q to be written but found %q", s, w.buf.String())
+	}
+
+	if expected := (len(s) + 2) / 3; w.calls != expected {
+		t.Errorf("expected %d calls to Write but found %d", expected, w.calls)
+	}
+}
+
+func TestWriteAllEmpty(t *testing.T) {
+	w := &shortWriter{limit: 3}
+	if err := writeAll(w, ""); err != nil {
+		t.Fatalf("writeAll returned error: %v", err)
+	}
+
+	if w.calls != 0 {
+		t.Errorf("expected no calls to Write but found %d", w.calls)
+	}
+}
+
+func TestWriteAllStuck(t *testing.T) {
+	if err := writeAll(stuckWriter{}, "list-sessions\n"); !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("expected io.ErrShortWrite but found %v", err)
+	}
+}
+
+func TestWriteAllError(t *testing.T) {
+	w := &failingWriter{limit: 5}
+	if err := writeAll(w, "list-sessions\n"); !errors.Is(err, errWriteFailed) {
+		t.Errorf("expected the writer's error but found %v", err)
+	}
+
+	if w.buf.String() != "list-" {
+		t.Errorf("expected %q to be written before the error but found %q", "list-", w.buf.String())
+	}
+}
+
+func TestLineScannerMaxLineLength(t *testing.T) {
+	input := "short\n" + strings.Repeat("x", 100) + "\nafter\n"
+
+	scanner := newLineScanner(strings.NewReader(input), 64)
+
+	if !scanner.Scan() || scanner.Text() != "short" {
+		t.Fatalf("expected to read %q first but found %q, %v", "short", scanner.Text(), scanner.Err())
+	}
+	if scanner.Scan() {
+		t.Fatalf("expected a line longer than the limit to fail but read %q", scanner.Text())
+	}
+	if !errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		t.Errorf("expected bufio.ErrTooLong but found %v", scanner.Err())
+	}
+}
+
+func TestLineScannerDefaultMaxLineLength(t *testing.T) {
+	// Longer than the 64KB bufio.Scanner allows by default
+	line := strings.Repeat("x", 100*1024)
+
+	scanner := newLineScanner(strings.NewReader(line+"\n"), 0)
+	if !scanner.Scan() || scanner.Text() != line {
+		t.Errorf("expected to read a line of %d bytes but found %d bytes, %v", len(line), len(scanner.Text()), scanner.Err())
+	}
+}
+
+func TestReadNextLineAfterReadError(t *testing.T) {
+	lines := make(chan string)
+	close(lines)
+	readErr := bufio.ErrTooLong
+
+	r := &Runner{lines: lines, readErr: &readErr}
+
+	_, err := r.readNextLine(context.Background())
+	if !errors.Is(err, ErrConnectionLost) {
+		t.Errorf("expected an error wrapping ErrConnectionLost but found %v", err)
+	}
+	if !strings.Contains(err.Error(), bufio.ErrTooLong.Error()) {
+		t.Errorf("expected the error to say why reading stopped but found %v", err)
+	}
+}
+
+func TestShortCommand(t *testing.T) {
+	if cmd := "list-sessions"; shortCommand(cmd) != cmd {
+		t.Errorf("expected a short command to be kept but found %q", shortCommand(cmd))
+	}
+
+	long := "send-keys -t %1 " + strings.Repeat("é", 100)
+	short := shortCommand(long)
+	if !strings.HasPrefix(long, strings.TrimSuffix(short, "...")) || !strings.HasSuffix(short, "...") {
+		t.Errorf("expected the start of the command followed by '...' but found %q", short)
+	}
+	if len(short) > shortCommandLength+len("...") {
+		t.Errorf("expected at most %d bytes but found %d", shortCommandLength+len("..."), len(short))
+	}
+	if !utf8.ValidString(short) {
+		t.Errorf("expected the command to be cut between characters but found %q", short)
+	}
+}
+
+// Returns a Runner connected to a tmux server of its own, which is killed at
+// the end of the test. Skips the test if tmux isn't installed.
+func newTestRunner(t *testing.T, c Config) *Runner {
+	t.Helper()
+
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux isn't installed")
+	}
+
+	c.Socket = fmt.Sprintf("tmux-go-test-%d-%s", os.Getpid(), strings.ReplaceAll(t.Name(), "/", "-"))
+
+	r, err := NewRunner(c)
+	if err != nil {
+		t.Fatalf("NewRunner returned error: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = r.Close()
+		_, _ = Command(Config{Socket: c.Socket}, "kill-server")
+	})
+
+	return r
+}
+
+// Send lines to the runner's lines channel, as scanLines would
+func sendLines(r *Runner, lines ...string) {
+	go func() {
+		for _, line := range lines {
+			r.lines <- line
+		}
+	}()
+}
+
+func TestReadCommandOutputTimeoutBeforeOutput(t *testing.T) {
+	r := &Runner{lines: make(chan string)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := r.readCommandOutput(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded but found %v", err)
+	}
+	if r.skip != 1 {
+		t.Errorf("expected 1 block to skip but found %d", r.skip)
+	}
+
+	// The output of the command which timed out arrives late, followed by that
+	// of the next command
+	sendLines(r,
+		"%begin 1 1 1", "late", "%end 1 1 1",
+		"%begin 1 2 1", "next", "%end 1 2 1",
+	)
+
+	output, err := r.readCommandOutput(context.Background())
+	if err != nil || output != "next" {
+		t.Errorf("expected the next command's output %q but found %q, %v", "next", output, err)
+	}
+	if r.skip != 0 {
+		t.Errorf("expected no blocks left to skip but found %d", r.skip)
+	}
+}
+
+func TestReadCommandOutputTimeoutDuringOutput(t *testing.T) {
+	r := &Runner{lines: make(chan string)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	sendLines(r, "%begin 1 1 1", "first part")
+
+	if _, err := r.readCommandOutput(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded but found %v", err)
+	}
+
+	sendLines(r,
+		"second part", "%error 1 1 1",
+		"%begin 1 2 1", "next", "%end 1 2 1",
+	)
+
+	output, err := r.readCommandOutput(context.Background())
+	if err != nil || output != "next" {
+		t.Errorf("expected the next command's output %q but found %q, %v", "next", output, err)
+	}
+	if r.skip != 0 {
+		t.Errorf("expected no blocks left to skip but found %d", r.skip)
+	}
+}
+
+func TestReadCommandOutputError(t *testing.T) {
+	r := &Runner{lines: make(chan string)}
+
+	sendLines(r, "%begin 1 1 1", "can't find session: foo", "%error 1 1 1")
+
+	_, err := r.readCommandOutput(context.Background())
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Message != "can't find session: foo" {
+		t.Errorf("expected a CommandError with tmux's message but found %v", err)
+	}
+}
+
+func TestScanLinesSeparatesNotifications(t *testing.T) {
+	input := strings.Join([]string{
+		"%output %1 before",
+		"%begin 1 1 1",
+		"%1",
+		"%output %1 looks like a notification",
+		"%end 1 1 1",
+		"%window-add @1",
+		"%output %1 between",
+		"%begin 1 2 1",
+		"%end 1 2 1",
+		"%output %1 after",
+	}, "\n") + "\n"
+
+	var notifications []string
+	r := &Runner{OnNotification: func(line string) {
+		notifications = append(notifications, line)
+	}}
+
+	lines := make(chan string)
+	readErr := new(error)
+	go r.scanLines(newLineScanner(strings.NewReader(input), 0), lines, make(chan struct{}), readErr)
+
+	var output []string
+	for line := range lines {
+		output = append(output, line)
+	}
+
+	// tmux holds notifications back until the output of the command being run
+	// is finished, so lines inside a block are output even if they start
+	// with "%"
+	expectedOutput := []string{
+		"%begin 1 1 1",
+		"%1",
+		"%output %1 looks like a notification",
+		"%end 1 1 1",
+		"%begin 1 2 1",
+		"%end 1 2 1",
+	}
+	expectedNotifications := []string{
+		"%output %1 before",
+		"%window-add @1",
+		"%output %1 between",
+		"%output %1 after",
+	}
+
+	if !reflect.DeepEqual(output, expectedOutput) {
+		t.Errorf("expected output lines %q but found %q", expectedOutput, output)
+	}
+	if !reflect.DeepEqual(notifications, expectedNotifications) {
+		t.Errorf("expected notifications %q but found %q", expectedNotifications, notifications)
+	}
+	if *readErr != nil {
+		t.Errorf("expected no read error but found %v", *readErr)
+	}
+}
+
+func TestCloseZeroValue(t *testing.T) {
+	var r Runner
+	if err := r.Close(); err != nil {
+		t.Errorf("expected closing a Runner which was never started to succeed but found %v", err)
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	r := newTestRunner(t, Config{})
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("expected closing a second time to succeed but found %v", err)
+	}
+}
+
+func TestNewRunnerBadBinaryPath(t *testing.T) {
+	r, err := NewRunner(Config{BinaryPath: "/nonexistent/tmux"})
+	if err == nil {
+		t.Fatal("expected an error for a tmux binary which doesn't exist")
+	}
+	if r != nil {
+		t.Errorf("expected no Runner with the error but found %v", r)
+	}
+}
+
+// A writer which is safe to write from one goroutine and read from another
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogger(t *testing.T) {
+	var buf syncBuffer
+	r := newTestRunner(t, Config{Logger: log.New(&buf, "", 0)})
+
+	if _, err := r.Run("display-message -p 'logged output'"); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if _, err := r.RunBatch([]string{"display-message -p one", "display-message -p two"}); err != nil {
+		t.Fatalf("RunBatch returned error: %v", err)
+	}
+
+	logged := buf.String()
+	for _, expected := range []string{
+		"> display-message -p 'logged output'\n",
+		"< logged output\n",
+		"> display-message -p one\n",
+		"> display-message -p two\n",
+	} {
+		if !strings.Contains(logged, expected) {
+			t.Errorf("expected the log to contain %q but found %q", expected, logged)
+		}
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	r := newTestRunner(t, Config{CommandTimeout: 100 * time.Millisecond})
+
+	// tmux replies to run-shell at once, but holds back the commands after it
+	// until the shell command finishes
+	if _, err := r.Run("run-shell 'sleep 1'"); err != nil {
+		t.Fatalf("run-shell returned error: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := r.Run("display-message -p late"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded but found %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected Run to give up after the timeout but it took %v", elapsed)
+	}
+
+	// The timeout doesn't apply to a context given to RunContext, which
+	// waits past it, and the late reply is passed over
+	output, err := r.RunContext(context.Background(), "display-message -p ok")
+	if err != nil || output != "ok" {
+		t.Errorf("expected %q but found %q, %v", "ok", output, err)
+	}
+}
+
+// Run with -race to check the runner's locking
+func TestConcurrentRun(t *testing.T) {
+	r := newTestRunner(t, Config{})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*20)
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < 20; i++ {
+				expected := fmt.Sprintf("%d-%d", g, i)
+				output, err := r.Run(fmt.Sprintf("display-message -p '%s'", expected))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if output != expected {
+					errs <- fmt.Errorf("expected %q but found %q", expected, output)
+					return
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	var zero Runner
+	if err := zero.Ping(); err == nil {
+		t.Error("expected Ping on a Runner which was never started to fail")
+	}
+
+	r := newTestRunner(t, Config{})
+
+	if err := r.Ping(); err != nil {
+		t.Fatalf("Ping returned error: %v", err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if err := r.Ping(); err == nil {
+		t.Error("expected Ping after Close to fail")
+	}
+}
@@ -1,8 +1,13 @@
 package tmux
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Get the name of the active session. Returns an error if there is no active
@@ -22,7 +27,7 @@ func GetActiveSession(c Config) (string, error) {
 
 // Attach to the session with the provided name
 func (r *Runner) AttachSession(sessionName string) error {
-	_, err := r.Run(fmt.Sprintf("attach -t '%s'", sessionName))
+	_, err := r.Run(fmt.Sprintf("attach -t %s", Quote(sessionName)))
 	return err
 }
 
@@ -38,28 +43,424 @@ func (r *Runner) ListSessions() ([]string, error) {
 
 }
 
+// Returns true if a session with exactly the given name is running. tmux
+// reporting that there is no such session isn't treated as an error.
+func (r *Runner) HasSession(name string) (bool, error) {
+	_, err := r.Run(fmt.Sprintf("has-session -t %s", Quote("="+name)))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// A session, as returned by [Runner.ListSessionsDetailed]
+type Session struct {
+	// The session's name
+	Name string
+
+	// The number of windows in the session
+	Windows int
+
+	// True if at least one client is attached to the session
+	Attached bool
+
+	// When the session was created
+	Created time.Time
+}
+
+// Returns the running sessions, with details of each. See
+// [Runner.ListSessions] for just their names.
+func (r *Runner) ListSessionsDetailed() ([]Session, error) {
+	var err error
+
+	var output string
+	var format string = joinFields("#{session_name}", "#{session_windows}", "#{session_attached}", "#{session_created}")
+	if output, err = r.Run(fmt.Sprintf("list-sessions -F %s", Quote(format))); err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0)
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		var fields []string
+		if fields, err = splitFields(line, 4); err != nil {
+			return nil, err
+		}
+
+		s := Session{Name: fields[0]}
+
+		if s.Windows, err = strconv.Atoi(fields[1]); err != nil {
+			return nil, fmt.Errorf("error parsing window count of line '%s': '%s'", line, err.Error())
+		}
+
+		var attached int
+		if attached, err = strconv.Atoi(fields[2]); err != nil {
+			return nil, fmt.Errorf("error parsing attached client count of line '%s': '%s'", line, err.Error())
+		}
+		s.Attached = attached > 0
+
+		var created int64
+		if created, err = strconv.ParseInt(fields[3], 10, 64); err != nil {
+			return nil, fmt.Errorf("error parsing creation time of line '%s': '%s'", line, err.Error())
+		}
+		s.Created = time.Unix(created, 0)
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, nil
+}
+
+// A group of sessions which share the same windows, as returned by
+// [Runner.ListSessionGroups]
+type SessionGroup struct {
+	// The group's name, which is the name of the session the others were
+	// grouped with when they were created, unless that session has since been
+	// renamed or killed
+	Name string
+
+	// The names of the sessions in the group
+	Sessions []string
+}
+
+// Returns the session groups, in the order of their first session in
+// list-sessions. Sessions which aren't in a group are left out.
+func (r *Runner) ListSessionGroups() ([]SessionGroup, error) {
+	var err error
+
+	var output string
+	if output, err = r.Run(fmt.Sprintf("list-sessions -F %s", Quote(joinFields("#{session_group}", "#{session_name}")))); err != nil {
+		return nil, err
+	}
+
+	groups := make([]SessionGroup, 0)
+	index := make(map[string]int)
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		var fields []string
+		if fields, err = splitFields(line, 2); err != nil {
+			return nil, err
+		}
+
+		group, session := fields[0], fields[1]
+		if group == "" {
+			continue
+		}
+
+		i, ok := index[group]
+		if !ok {
+			i = len(groups)
+			index[group] = i
+			groups = append(groups, SessionGroup{Name: group})
+		}
+		groups[i].Sessions = append(groups[i].Sessions, session)
+	}
+
+	return groups, nil
+}
+
 // Start a new session
 func (r *Runner) StartSession(name string) error {
-	sessions, err := r.ListSessions()
+	sessionRunning, err := r.HasSession(name)
 	if err != nil {
 		return err
 	}
 
-	sessionRunning := false
+	if !sessionRunning {
+		_, err := r.Run(fmt.Sprintf("new-session -d -s %s", Quote(name)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+
+}
+
+// Switch the most recently active client to the session with the most recent
+// activity, other than the runner's own control session, as
+// [Runner.SwitchClient] does. Returns an error wrapping [ErrNotFound] if there
+// is no other session, or no client other than the runner's own.
+func (r *Runner) AttachMostRecent() error {
+	var err error
+
+	var output string
+	if output, err = r.Run("list-sessions -F '#{session_activity} #{session_name}'"); err != nil {
+		return err
+	}
+
+	var mostRecent string
+	var mostRecentActivity int64 = -1
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		tokens := strings.SplitN(line, " ", 2)
+		if len(tokens) != 2 {
+			return fmt.Errorf("expected line to be an activity time and a session name separated by a space but found '%s'", line)
+		}
+
+		var activity int64
+		if activity, err = strconv.ParseInt(tokens[0], 10, 64); err != nil {
+			return fmt.Errorf("error parsing activity time of line '%s': '%s'", line, err.Error())
+		}
+
+		if tokens[1] == r.ownSession() {
+			continue
+		}
+
+		if activity > mostRecentActivity {
+			mostRecent = tokens[1]
+			mostRecentActivity = activity
+		}
+	}
+
+	if mostRecentActivity < 0 {
+		return fmt.Errorf("no sessions to attach to other than the runner's control session: %w", ErrNotFound)
+	}
+
+	// Attaching from the runner would move its own control-mode client
+	// rather than the user's, so the user's client is switched instead
+	return r.SwitchClient("=" + mostRecent)
+}
+
+// Kill the session with the given name. Returns the tmux error if there is no
+// such session.
+func (r *Runner) KillSession(name string) error {
+	// "=" so that the name isn't matched as a prefix of another session's
+	_, err := r.Run(fmt.Sprintf("kill-session -t %s", Quote("="+name)))
+	return err
+}
+
+// Rename the session named oldName to newName. Returns the tmux error if there
+// is no session named oldName.
+func (r *Runner) RenameSession(oldName, newName string) error {
+	_, err := r.Run(fmt.Sprintf("rename-session -t %s %s", Quote("="+oldName), Quote(newName)))
+	return err
+}
+
+// Returns desired if no session has that name, otherwise the first of
+// "desired-2", "desired-3", etc. which no session has. The session named
+// ignore, if any, doesn't count as having its name.
+func (r *Runner) uniqueSessionName(desired string, ignore string) (string, error) {
+	sessions, err := r.ListSessions()
+	if err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool)
 	for _, s := range sessions {
-		if s == name {
-			sessionRunning = true
-			break
+		if s != ignore {
+			taken[s] = true
 		}
 	}
 
-	if !sessionRunning {
-		_, err := r.Run(fmt.Sprintf("new-session -d -s %s", name))
+	name := desired
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s-%d", desired, i)
+	}
+
+	return name, nil
+}
+
+// Returns a session name based on desired which no running session has:
+// desired itself if it's free, otherwise the first free name of "desired-2",
+// "desired-3", etc.
+func (r *Runner) SuggestSessionName(desired string) (string, error) {
+	return r.uniqueSessionName(desired, "")
+}
+
+// Rename the session named oldName to desiredName, or, if another session
+// already has that name, to the first free name suggested by
+// [Runner.SuggestSessionName]. Returns the session's new name. If the session
+// already has the name that would be chosen, nothing is changed.
+func (r *Runner) RenameSessionUnique(oldName, desiredName string) (string, error) {
+	if oldName == desiredName {
+		return oldName, nil
+	}
+
+	name, err := r.uniqueSessionName(desiredName, oldName)
+	if err != nil {
+		return "", err
+	}
+
+	if name == oldName {
+		return oldName, nil
+	}
+
+	if err = r.RenameSession(oldName, name); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// The default for [Config.ExitKeys]: interrupt whatever is running, then tell
+// the shell to exit
+var DefaultExitKeys = []string{"C-c", "exit", "Enter"}
+
+// How often GracefulKillSession checks whether the session has gone
+const gracefulKillPollInterval = 100 * time.Millisecond
+
+// Shut down the session with the given name by asking the program in each of
+// its panes to exit, rather than killing them outright. The keys in
+// [Config.ExitKeys] are sent to each pane, and then the session is polled
+// until it goes away, which tmux does once all of its panes have closed. If
+// ctx is done first, the session is killed with [Runner.KillSession].
+//
+// Returns nil once the session is gone, whether it closed by itself or had to
+// be killed.
+func (r *Runner) GracefulKillSession(ctx context.Context, name string) error {
+	var err error
+
+	var output string
+	// "=name:" so that it matches neither another session whose name starts
+	// with name, nor a window called name
+	if output, err = r.Run(fmt.Sprintf("list-panes -s -t %s -F '#{pane_id}'", Quote("="+name+":"))); err != nil {
+		return err
+	}
+
+	exitKeys := r.Config.ExitKeys
+	if exitKeys == nil {
+		exitKeys = DefaultExitKeys
+	}
+
+	panes := strings.Split(Trim(output), "\n")
+	for _, pane := range panes {
+		// A pane may have closed since the panes were listed
+		if err = r.SendKeys(pane, exitKeys...); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(gracefulKillPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var running bool
+		if running, err = r.HasSession(name); err != nil {
+			return err
+		}
+		if !running {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			// Kill the session without checking for it first, since it may
+			// close by itself at any moment; if it already has, that's fine
+			if err = r.KillSession(name); err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Wait until a session with the given name exists, checking every interval,
+// or until ctx is done, in which case ctx.Err() is returned. Returns nil at
+// once if the session already exists. If interval isn't positive, the session
+// is checked every 100ms.
+func (r *Runner) WaitForSession(ctx context.Context, name string, interval time.Duration) error {
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		exists, err := r.HasSession(name)
 		if err != nil {
 			return err
 		}
+		if exists {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Returns true if the session with the given name is the only session apart
+// from the runner's own control session, so that killing it, and then closing
+// the runner, would leave no sessions and stop the tmux server. Returns an
+// error wrapping [ErrNotFound] if there is no such session.
+func (r *Runner) IsLastSession(name string) (bool, error) {
+	sessions, err := r.ListSessions()
+	if err != nil {
+		return false, err
 	}
 
-	return nil
+	found := false
+	others := 0
+	for _, s := range sessions {
+		switch s {
+		case name:
+			found = true
+		case r.ownSession():
+		default:
+			others++
+		}
+	}
+
+	if !found {
+		return false, fmt.Errorf("no session named '%s': %w", name, ErrNotFound)
+	}
+
+	return others == 0, nil
+}
+
+// The size NewSessionMatchingTerminal uses when it can't find the size of the
+// terminal, which is also tmux's own default
+const (
+	defaultSessionWidth  = 80
+	defaultSessionHeight = 24
+)
+
+// Returns the size of the terminal the program is running in, from the
+// COLUMNS and LINES environment variables if both are set, otherwise from the
+// terminal connected to standard output. If neither gives a size, returns
+// 80x24.
+func hostTerminalSize() (int, int) {
+	columns, columnsErr := strconv.Atoi(os.Getenv("COLUMNS"))
+	lines, linesErr := strconv.Atoi(os.Getenv("LINES"))
+	if columnsErr == nil && linesErr == nil && columns > 0 && lines > 0 {
+		return columns, lines
+	}
+
+	if width, height, ok := terminalSize(os.Stdout); ok {
+		return width, height
+	}
+
+	return defaultSessionWidth, defaultSessionHeight
+}
+
+// Start a new detached session with the given name, sized to match the
+// terminal the program is running in, and return its ID, like "$1". A session
+// which is later attached from that terminal then doesn't need to be resized
+// and redrawn.
+//
+// The size comes from the COLUMNS and LINES environment variables if both are
+// set, otherwise from the terminal connected to standard output. If standard
+// output isn't a terminal either, the session is 80x24.
+func (r *Runner) NewSessionMatchingTerminal(name string) (string, error) {
+	width, height := hostTerminalSize()
+
+	output, err := r.Run(fmt.Sprintf("new-session -d -P -F '#{session_id}' -s %s -x %d -y %d", Quote(name), width, height))
+	if err != nil {
+		return "", err
+	}
 
+	return Trim(output), nil
 }
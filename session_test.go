@@ -0,0 +1,50 @@
+package tmux
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGracefulKillSessionExits(t *testing.T) {
+	r := newTestRunner(t, Config{})
+
+	// C-c, the first of the exit keys, ends sleep, which closes the pane and
+	// with it the session
+	if _, err := r.Run("new-session -d -s graceful 'sleep 60'"); err != nil {
+		t.Fatalf("new-session returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.GracefulKillSession(ctx, "graceful"); err != nil {
+		t.Fatalf("GracefulKillSession returned error: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("expected the session to exit by itself before the timeout")
+	}
+
+	if running, err := r.HasSession("graceful"); err != nil || running {
+		t.Errorf("expected the session to be gone but found %v, %v", running, err)
+	}
+}
+
+func TestGracefulKillSessionKilled(t *testing.T) {
+	r := newTestRunner(t, Config{ExitKeys: []string{"ignored"}})
+
+	if _, err := r.Run("new-session -d -s graceful 'sleep 60'"); err != nil {
+		t.Fatalf("new-session returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	if err := r.GracefulKillSession(ctx, "graceful"); err != nil {
+		t.Fatalf("GracefulKillSession returned error: %v", err)
+	}
+
+	if running, err := r.HasSession("graceful"); err != nil || running {
+		t.Errorf("expected the session to be gone but found %v, %v", running, err)
+	}
+}
@@ -0,0 +1,35 @@
+package tmux
+
+import "context"
+
+// The most commonly used methods of a [Runner]. Code which takes a
+// SessionRunner rather than a *Runner can be tested with a fake implementation
+// instead of a real tmux server. Anything not covered here can be done with
+// Run, since every other method is built on it.
+type SessionRunner interface {
+	Run(cmd string) (string, error)
+	RunArgs(args ...string) (string, error)
+	RunContext(ctx context.Context, cmd string) (string, error)
+	RunBatch(cmds []string) ([]string, error)
+	Close() error
+
+	ListSessions() ([]string, error)
+	HasSession(name string) (bool, error)
+	StartSession(name string) error
+	AttachSession(sessionName string) error
+	KillSession(name string) error
+	RenameSession(oldName, newName string) error
+
+	GetActiveWindow() (string, error)
+	ListWindows() ([]Window, error)
+	NewWindow(opts NewWindowOptions) (string, error)
+	SelectWindow(target string) error
+
+	ListPanes() ([]Pane, error)
+	SplitWindow(target string, vertical bool) (string, error)
+	SelectPane(target string) error
+	SendKeys(target string, keys ...string) error
+	CapturePane(target string) (string, error)
+}
+
+var _ SessionRunner = (*Runner)(nil)
@@ -0,0 +1,79 @@
+package tmux
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// A SessionRunner which keeps a list of sessions instead of running tmux, as a
+// consumer of the package might write. Calling a method it doesn't implement
+// panics, through the nil embedded interface.
+type fakeSessionRunner struct {
+	SessionRunner
+
+	sessions map[string]bool
+}
+
+func (f *fakeSessionRunner) ListSessions() ([]string, error) {
+	var names []string
+	for name := range f.sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *fakeSessionRunner) HasSession(name string) (bool, error) {
+	return f.sessions[name], nil
+}
+
+func (f *fakeSessionRunner) StartSession(name string) error {
+	f.sessions[name] = true
+	return nil
+}
+
+func (f *fakeSessionRunner) KillSession(name string) error {
+	if !f.sessions[name] {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(f.sessions, name)
+	return nil
+}
+
+// Code under test, which only needs a SessionRunner: start a session with the
+// given name, replacing any session of that name already running
+func restartSession(sr SessionRunner, name string) error {
+	running, err := sr.HasSession(name)
+	if err != nil {
+		return err
+	}
+
+	if running {
+		if err := sr.KillSession(name); err != nil {
+			return err
+		}
+	}
+
+	return sr.StartSession(name)
+}
+
+func TestFakeSessionRunner(t *testing.T) {
+	f := &fakeSessionRunner{sessions: map[string]bool{"work": true}}
+
+	for _, name := range []string{"work", "play"} {
+		if err := restartSession(f, name); err != nil {
+			t.Fatalf("restartSession(%q) returned error: %v", name, err)
+		}
+	}
+
+	sessions, err := f.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions returned error: %v", err)
+	}
+
+	if expected := []string{"play", "work"}; !reflect.DeepEqual(sessions, expected) {
+		t.Errorf("expected sessions %q but found %q", expected, sessions)
+	}
+}
@@ -0,0 +1,105 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Attributes which may appear in a style, on their own or prefixed with "no"
+var styleAttributes = map[string]bool{
+	"bright":            true,
+	"bold":              true,
+	"dim":               true,
+	"underscore":        true,
+	"blink":             true,
+	"reverse":           true,
+	"hidden":            true,
+	"italics":           true,
+	"overline":          true,
+	"strikethrough":     true,
+	"double-underscore": true,
+	"curly-underscore":  true,
+	"dotted-underscore": true,
+	"dashed-underscore": true,
+	"acs":               true,
+}
+
+// Words other than attributes which may appear in a style, for things like the
+// status line's lists and ranges. Some, like "noattr" and "set-default", are
+// only known to versions of tmux newer than 3.3.
+var styleKeywords = map[string]bool{
+	"default":      true,
+	"none":         true,
+	"ignore":       true,
+	"noignore":     true,
+	"push-default": true,
+	"pop-default":  true,
+	"set-default":  true,
+	"noalign":      true,
+	"nolist":       true,
+	"norange":      true,
+	"noattr":       true,
+}
+
+// Settings in a style, like "align=centre", whose values aren't colors; tmux
+// checks those values itself. "width" and "pad" are only known to versions of
+// tmux newer than 3.3.
+var styleSettings = map[string]bool{
+	"align": true,
+	"list":  true,
+	"range": true,
+	"width": true,
+	"pad":   true,
+}
+
+// Check that s is a style which tmux accepts, like "bg=colour196,fg=white" or
+// "fg=red bold". Returns an error naming the first part of the style which
+// isn't valid. Only colors are checked closely; the values of other settings,
+// like "align=centre", are left to tmux.
+func validateStyle(s string) error {
+	parts := strings.FieldsFunc(s, func(c rune) bool { return c == ',' || c == ' ' })
+	for _, part := range parts {
+		if key, value, found := strings.Cut(part, "="); found {
+			switch key {
+			case "fg", "bg", "us", "fill":
+				if !isValidColor(value) {
+					return fmt.Errorf("invalid color '%s' in style '%s'", value, s)
+				}
+			default:
+				if !styleSettings[key] {
+					return fmt.Errorf("unknown style setting '%s' in style '%s'", key, s)
+				}
+			}
+			continue
+		}
+
+		if styleKeywords[part] {
+			continue
+		}
+
+		if styleAttributes[strings.TrimPrefix(part, "no")] {
+			continue
+		}
+
+		return fmt.Errorf("unknown attribute '%s' in style '%s'", part, s)
+	}
+
+	return nil
+}
+
+// Set the style of a single pane, like "bg=colour52" to tint it red, with
+// "select-pane -P". This is the pane's own window-style option, which takes
+// precedence over the window-style and window-active-style options of its
+// window; those apply to every pane in the window, depending on whether it is
+// the active pane, so use [Runner.SetOption] with them to style panes by
+// whether they have focus rather than by which pane they are.
+//
+// Returns an error without changing anything if the style isn't valid.
+func (r *Runner) SetPaneStyle(pane, style string) error {
+	if err := validateStyle(style); err != nil {
+		return err
+	}
+
+	_, err := r.Run(fmt.Sprintf("select-pane -t %s -P %s", Quote(pane), Quote(style)))
+	return err
+}
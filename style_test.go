@@ -0,0 +1,50 @@
+package tmux
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateStyle(t *testing.T) {
+	tests := []struct {
+		style string
+		valid bool
+	}{
+		{"", true},
+		{"default", true},
+		{"fg=red", true},
+		{"bg=colour196,fg=white", true},
+		{"fg=#ff0000 bg=black", true},
+		{"fg=red bold", true},
+		{"bold,italics,nounderscore", true},
+		{"us=red,curly-underscore", true},
+		{"fill=colour235", true},
+		{"align=centre,list=on", true},
+		{"range=window|1", true},
+		{"push-default", true},
+		{"noattr", true},
+		{"fg=colour256", false},
+		{"fg=#ff00", false},
+		{"bg=", false},
+		{"colour=red", false},
+		{"bolder", false},
+		{"fg=red,blink,nosuch", false},
+	}
+
+	for _, test := range tests {
+		err := validateStyle(test.style)
+		if test.valid && err != nil {
+			t.Errorf("validateStyle(%q) returned error: %v", test.style, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("validateStyle(%q): expected an error", test.style)
+		}
+	}
+}
+
+func TestValidateStyleNamesPart(t *testing.T) {
+	err := validateStyle("fg=red,blink,nosuch")
+	if err == nil || !strings.Contains(err.Error(), "'nosuch'") {
+		t.Errorf("expected the error to name the invalid part but found %v", err)
+	}
+}
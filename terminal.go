@@ -0,0 +1,31 @@
+package tmux
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Terminal names are terminfo entry names, like "tmux-256color"
+var terminalName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// Get the default-terminal option: the value of TERM for programs started in
+// new windows, like "tmux-256color" or "screen-256color"
+func (r *Runner) GetDefaultTerminal() (string, error) {
+	return r.GetOption(OptionScope{Global: true}, "default-terminal")
+}
+
+// Set the default-terminal option, which is the value of TERM for programs
+// started in new windows. It affects which escape sequences those programs
+// use, and so whether colors show up in captured output. Windows which already
+// exist keep the value they started with.
+//
+// Returns an error without setting anything if term doesn't look like a
+// terminfo entry name. Whether the entry is installed where the programs run
+// isn't checked.
+func (r *Runner) SetDefaultTerminal(term string) error {
+	if !terminalName.MatchString(term) {
+		return fmt.Errorf("expected a terminfo entry name like 'tmux-256color' but found '%s'", term)
+	}
+
+	return r.SetOption(OptionScope{Global: true}, "default-terminal", term)
+}
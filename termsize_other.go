@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package tmux
+
+import "os"
+
+// Returns the width and height of the terminal f is connected to, or false if
+// it isn't connected to a terminal. Not supported on this platform.
+func terminalSize(f *os.File) (int, int, bool) {
+	return 0, 0, false
+}
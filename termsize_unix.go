@@ -0,0 +1,27 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package tmux
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Returns the width and height of the terminal f is connected to, or false if
+// it isn't connected to a terminal
+func terminalSize(f *os.File) (int, int, bool) {
+	var ws struct {
+		Row    uint16
+		Col    uint16
+		Xpixel uint16
+		Ypixel uint16
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.Col == 0 || ws.Row == 0 {
+		return 0, 0, false
+	}
+
+	return int(ws.Col), int(ws.Row), true
+}
@@ -0,0 +1,125 @@
+package tmux
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Colors for the parts of the tmux interface, applied with
+// [Runner.ApplyTheme]. Each color may be a name, like "red", "brightblue",
+// "default", or, with tmux 3.2 or later, an X11 name like "darkgreen", a
+// palette color, like "colour208" or "color208", or an RGB color, like
+// "#ff8700". Empty fields are left as they are.
+type Theme struct {
+	// The text and background of the status line (status-style)
+	StatusForeground string
+	StatusBackground string
+
+	// The borders of inactive panes (pane-border-style)
+	PaneBorder string
+
+	// The border of the active pane (pane-active-border-style)
+	ActivePaneBorder string
+
+	// The background of the active pane, to highlight it (window-active-style)
+	ActivePaneBackground string
+
+	// The text and background of messages and the command prompt
+	// (message-style)
+	MessageForeground string
+	MessageBackground string
+}
+
+var paletteColor = regexp.MustCompile(`^colou?r([0-9]+)$`)
+var rgbColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Names like "red", "brightred", "default", or X11 names like "darkgreen" and
+// "grey50", which tmux 3.2 and later accepts
+var namedColor = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+
+// Returns true if s has the form of a color tmux accepts. Names are only
+// checked for their form, since which names tmux knows depends on its version;
+// tmux reports an unknown one when it is used.
+func isValidColor(s string) bool {
+	if m := paletteColor.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		return err == nil && n <= 255
+	}
+
+	return rgbColor.MatchString(s) || namedColor.MatchString(s)
+}
+
+// Build a style, like "fg=red,bg=black", from the non-empty colors
+func themeStyle(fg, bg string) string {
+	parts := make([]string, 0, 2)
+	if fg != "" {
+		parts = append(parts, "fg="+fg)
+	}
+	if bg != "" {
+		parts = append(parts, "bg="+bg)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// Set the global options for the colors in the theme, in a single batch of
+// commands. The colors are added to the existing styles with "set-option -a",
+// so attributes the theme doesn't mention, like bold, are kept. If any colors
+// aren't in the form of a color, nothing is set, and the error names the
+// fields which have them; a color name tmux doesn't know is reported by tmux,
+// as a [CommandError], once the other colors have been set.
+func (r *Runner) ApplyTheme(theme Theme) error {
+	colors := []struct {
+		field string
+		value string
+	}{
+		{"StatusForeground", theme.StatusForeground},
+		{"StatusBackground", theme.StatusBackground},
+		{"PaneBorder", theme.PaneBorder},
+		{"ActivePaneBorder", theme.ActivePaneBorder},
+		{"ActivePaneBackground", theme.ActivePaneBackground},
+		{"MessageForeground", theme.MessageForeground},
+		{"MessageBackground", theme.MessageBackground},
+	}
+
+	invalid := make([]string, 0)
+	for _, c := range colors {
+		if c.value != "" && !isValidColor(c.value) {
+			invalid = append(invalid, fmt.Sprintf("%s '%s'", c.field, c.value))
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid colors in theme: %s", strings.Join(invalid, ", "))
+	}
+
+	sessionOptions := OptionScope{Kind: SessionOption, Global: true}
+	windowOptions := OptionScope{Kind: WindowOption, Global: true}
+
+	options := []struct {
+		scope OptionScope
+		name  string
+		style string
+	}{
+		{sessionOptions, "status-style", themeStyle(theme.StatusForeground, theme.StatusBackground)},
+		{windowOptions, "pane-border-style", themeStyle(theme.PaneBorder, "")},
+		{windowOptions, "pane-active-border-style", themeStyle(theme.ActivePaneBorder, "")},
+		{windowOptions, "window-active-style", themeStyle("", theme.ActivePaneBackground)},
+		{sessionOptions, "message-style", themeStyle(theme.MessageForeground, theme.MessageBackground)},
+	}
+
+	cmds := make([]string, 0, len(options))
+	for _, o := range options {
+		if o.style != "" {
+			cmds = append(cmds, fmt.Sprintf("set-option -a %s %s %s", o.scope.flags(), Quote(o.name), Quote(o.style)))
+		}
+	}
+
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	_, err := r.RunBatch(cmds)
+	return err
+}
@@ -0,0 +1,39 @@
+package tmux
+
+import "testing"
+
+func TestIsValidColor(t *testing.T) {
+	tests := []struct {
+		color string
+		valid bool
+	}{
+		{"red", true},
+		{"brightred", true},
+		{"default", true},
+		{"terminal", true},
+		{"colour0", true},
+		{"colour255", true},
+		{"color196", true},
+		{"colour256", false},
+		{"colour1000", false},
+		{"colour", true},
+		{"colour-1", false},
+		{"#ff0000", true},
+		{"#FF00aa", true},
+		{"#ff000", false},
+		{"#ff00000", false},
+		{"#gg0000", false},
+		{"ff0000", true},
+		{"", false},
+		{"red,bold", false},
+		{"fg=red", false},
+		{"red blue", false},
+		{"1red", false},
+	}
+
+	for _, test := range tests {
+		if actual := isValidColor(test.color); actual != test.valid {
+			t.Errorf("isValidColor(%q): expected %v but found %v", test.color, test.valid, actual)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package tmux
 
-import "os/exec"
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
 
 const TmuxExec = "tmux"
 
@@ -9,3 +13,23 @@ const TmuxExec = "tmux"
 func Tmux() (string, error) {
 	return exec.LookPath("tmux")
 }
+
+// Get the path to the tmux executable to use with the given config: its
+// BinaryPath if set, otherwise the one found by [Tmux]. Returns an error if
+// BinaryPath is set but isn't an executable file.
+func tmuxBinary(c Config) (string, error) {
+	if c.BinaryPath == "" {
+		return Tmux()
+	}
+
+	info, err := os.Stat(c.BinaryPath)
+	if err != nil {
+		return "", fmt.Errorf("error checking tmux binary path '%s': '%s'", c.BinaryPath, err.Error())
+	}
+
+	if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+		return "", fmt.Errorf("tmux binary path '%s' is not an executable file", c.BinaryPath)
+	}
+
+	return c.BinaryPath, nil
+}
@@ -0,0 +1,57 @@
+package tmux
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTmuxBinaryFromPath(t *testing.T) {
+	dir := t.TempDir()
+	tmuxPath := filepath.Join(dir, "tmux")
+	writeFile(t, tmuxPath, "#!/bin/sh\n", 0755)
+	t.Setenv("PATH", dir)
+
+	path, err := tmuxBinary(Config{})
+	if err != nil || path != tmuxPath {
+		t.Errorf("expected %q from PATH but found %q, %v", tmuxPath, path, err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if path, err := tmuxBinary(Config{}); err == nil {
+		t.Errorf("expected an error with no tmux in PATH but found %q", path)
+	}
+}
+
+func TestTmuxBinaryOverride(t *testing.T) {
+	dir := t.TempDir()
+
+	// A tmux in PATH which BinaryPath takes precedence over
+	writeFile(t, filepath.Join(dir, "tmux"), "#!/bin/sh\n", 0755)
+	t.Setenv("PATH", dir)
+
+	executable := filepath.Join(dir, "my-tmux")
+	writeFile(t, executable, "#!/bin/sh\n", 0755)
+
+	notExecutable := filepath.Join(dir, "not-executable")
+	writeFile(t, notExecutable, "#!/bin/sh\n", 0644)
+
+	tests := []struct {
+		binaryPath string
+		valid      bool
+	}{
+		{executable, true},
+		{notExecutable, false},
+		{dir, false},
+		{filepath.Join(dir, "missing"), false},
+	}
+
+	for _, test := range tests {
+		path, err := tmuxBinary(Config{BinaryPath: test.binaryPath})
+		if test.valid && (err != nil || path != test.binaryPath) {
+			t.Errorf("expected %q but found %q, %v", test.binaryPath, path, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("expected an error for %q but found %q", test.binaryPath, path)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package tmux
 
+import "strings"
+
 // Given a string, returns a copy of the string with a trailing newline, if any,
-// removed
+// removed. Only a single "\n" is removed: "a\n\n" becomes "a\n", and "a\r\n"
+// becomes "a\r". To remove all trailing whitespace, use [TrimAll].
 func Trim(s string) string {
 	switch {
 	case len(s) == 0:
@@ -12,3 +15,10 @@ func Trim(s string) string {
 		return s
 	}
 }
+
+// Given a string, returns a copy of the string with all trailing whitespace
+// removed, including any number of newlines, carriage returns, spaces and
+// tabs. Leading whitespace is kept.
+func TrimAll(s string) string {
+	return strings.TrimRight(s, " \t\r\n")
+}
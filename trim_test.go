@@ -0,0 +1,44 @@
+package tmux
+
+import "testing"
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{"", ""},
+		{"a", "a"},
+		{"a\n", "a"},
+		{"a\n\n", "a\n"},
+		{"a\r\n", "a\r"},
+		{"\n", ""},
+	}
+
+	for _, test := range tests {
+		if actual := Trim(test.in); actual != test.expected {
+			t.Errorf("Trim(%q): expected %q but found %q", test.in, test.expected, actual)
+		}
+	}
+}
+
+func TestTrimAll(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{"", ""},
+		{"a", "a"},
+		{"a\n", "a"},
+		{"a\n\n\n", "a"},
+		{"a\r\n", "a"},
+		{"a \t\r\n \n", "a"},
+		{"  a", "  a"},
+		{"a\nb\n", "a\nb"},
+		{" \n\t", ""},
+	}
+
+	for _, test := range tests {
+		if actual := TrimAll(test.in); actual != test.expected {
+			t.Errorf("TrimAll(%q): expected %q but found %q", test.in, test.expected, actual)
+		}
+	}
+}
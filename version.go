@@ -0,0 +1,89 @@
+package tmux
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A tmux release, like 3.3 for "tmux 3.3a"
+type version struct {
+	major int
+	minor int
+}
+
+// Returns true if v is the same release as, or a later release than, major.minor
+func (v version) atLeast(major, minor int) bool {
+	return v.major > major || (v.major == major && v.minor >= minor)
+}
+
+// Parse the output of "tmux -V", like "tmux 3.3a" or "tmux next-3.4"
+func parseVersion(s string) (version, error) {
+	var err error
+
+	v := strings.TrimPrefix(strings.TrimSpace(s), "tmux ")
+
+	// Development and distribution builds look like "next-3.4" or
+	// "openbsd-7.3"; only "next-" is followed by a tmux version
+	v = strings.TrimPrefix(v, "next-")
+
+	tokens := strings.SplitN(v, ".", 2)
+	if len(tokens) != 2 {
+		return version{}, fmt.Errorf("expected a version like 'tmux 3.3a' but found '%s'", s)
+	}
+
+	// Ignore any suffix after the minor version, like the "a" in "3.3a"
+	minor := strings.TrimRightFunc(tokens[1], func(c rune) bool { return c < '0' || c > '9' })
+
+	var result version
+	if result.major, err = strconv.Atoi(tokens[0]); err != nil {
+		return version{}, fmt.Errorf("error parsing major version of '%s': '%s'", s, err.Error())
+	}
+	if result.minor, err = strconv.Atoi(minor); err != nil {
+		return version{}, fmt.Errorf("error parsing minor version of '%s': '%s'", s, err.Error())
+	}
+
+	return result, nil
+}
+
+// Returns the version of the tmux executable
+func tmuxVersion(c Config) (version, error) {
+	output, err := Command(c, "-V")
+	if err != nil {
+		return version{}, err
+	}
+
+	return parseVersion(string(output))
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+// Returns the version of tmux, like "3.3" for "tmux 3.3a", with any suffix
+// after the minor version left out. This is the version of the tmux
+// executable the config refers to, which is that of the server unless the
+// server was started by another tmux.
+func ServerVersion(c Config) (string, error) {
+	v, err := tmuxVersion(c)
+	if err != nil {
+		return "", err
+	}
+
+	return v.String(), nil
+}
+
+// Returns an error if the tmux the config refers to is older than
+// major.minor, naming both versions, and nil otherwise
+func RequireVersion(c Config, major, minor int) error {
+	v, err := tmuxVersion(c)
+	if err != nil {
+		return err
+	}
+
+	if !v.atLeast(major, minor) {
+		return fmt.Errorf("tmux %d.%d or later is required, but found %s", major, minor, v)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package tmux
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in           string
+		major, minor int
+	}{
+		{"tmux 2.8", 2, 8},
+		{"tmux 3.3a", 3, 3},
+		{"tmux next-3.4", 3, 4},
+		{"tmux 3.3a\n", 3, 3},
+		{"3.2", 3, 2},
+	}
+
+	for _, test := range tests {
+		v, err := parseVersion(test.in)
+		if err != nil {
+			t.Errorf("parseVersion(%q) returned error: %v", test.in, err)
+			continue
+		}
+		if v.major != test.major || v.minor != test.minor {
+			t.Errorf("parseVersion(%q): expected %d.%d but found %s", test.in, test.major, test.minor, v)
+		}
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, in := range []string{"", "tmux", "tmux master", "tmux openbsd-7.3", "tmux x.3"} {
+		if v, err := parseVersion(in); err == nil {
+			t.Errorf("parseVersion(%q): expected an error but found %s", in, v)
+		}
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	v := version{major: 3, minor: 2}
+
+	tests := []struct {
+		major, minor int
+		expected     bool
+	}{
+		{2, 9, true},
+		{3, 1, true},
+		{3, 2, true},
+		{3, 3, false},
+		{4, 0, false},
+	}
+
+	for _, test := range tests {
+		if actual := v.atLeast(test.major, test.minor); actual != test.expected {
+			t.Errorf("%s.atLeast(%d, %d): expected %v but found %v", v, test.major, test.minor, test.expected, actual)
+		}
+	}
+}
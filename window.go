@@ -8,32 +8,316 @@ import (
 
 // Get the ID of the active window, as a string like "@0"
 func (r *Runner) GetActiveWindow() (string, error) {
-	return r.Run("list-windows -F '#{window_id}' -f '#{m:#{window_active},1}'")
+	output, err := r.Run("list-windows -F '#{window_id}' -f '#{m:#{window_active},1}'")
+	if err != nil {
+		return "", err
+	}
+
+	return Trim(output), nil
 }
 
-// Get the dimensions of the active window
+// Get the width and height of the window in the runner's current session with
+// the given name or ID. If several windows have the name, the one with the
+// lowest index is used. Returns an error wrapping [ErrNotFound] if there is no
+// such window.
 func (r *Runner) GetWindowDimensions(windowName string) (int, int, error) {
-	// TODO: The windowName parameter is ignored, so this API is lying.
-	// ['list-windows', '-F', '#{window_width} #{window_height}', '-f', '#{m:#{window_active},1}']
+	windows, err := r.ListWindows()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, w := range windows {
+		if w.Name == windowName || w.ID == windowName {
+			return w.Width, w.Height, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("window not found: '%s': %w", windowName, ErrNotFound)
+}
+
+// Options for [Runner.NewWindow]. At most one of Session, After, and Before
+// may be set; if none are, the window is added to the runner's current
+// session.
+type NewWindowOptions struct {
+	// The session to add the window to, at the first free index
+	Session string
+
+	// The name of the new window. If empty, tmux names it automatically.
+	Name string
+
+	// If set, the new window is inserted right after this window, moving any
+	// later windows up an index
+	After string
+
+	// If set, the new window is inserted right before this window, moving it
+	// and any later windows up an index. Requires tmux 3.2 or later.
+	Before string
+}
+
+// Create a new window, and return its ID, as a string like "@1"
+func (r *Runner) NewWindow(opts NewWindowOptions) (string, error) {
 	var err error
 
+	targets := 0
+	for _, t := range []string{opts.Session, opts.After, opts.Before} {
+		if t != "" {
+			targets++
+		}
+	}
+	if targets > 1 {
+		return "", fmt.Errorf("expected at most one of Session, After, and Before to be set but found %d", targets)
+	}
+
+	if opts.Before != "" {
+		if err = r.requireFeature(FeatureNewWindowBefore, "inserting a window before another"); err != nil {
+			return "", err
+		}
+	}
+
+	var cmd string = "new-window -P -F '#{window_id}'"
+	switch {
+	case opts.Session != "":
+		cmd += fmt.Sprintf(" -t %s", Quote(opts.Session+":"))
+	case opts.After != "":
+		cmd += fmt.Sprintf(" -a -t %s", Quote(opts.After))
+	case opts.Before != "":
+		cmd += fmt.Sprintf(" -b -t %s", Quote(opts.Before))
+	}
+	if opts.Name != "" {
+		cmd += fmt.Sprintf(" -n %s", Quote(opts.Name))
+	}
+
 	var output string
-	if output, err = r.Run("list-windows -F '#{window_width} #{window_height}' -f '#{m:#{window_active},1}'"); err != nil {
-		return 0, 0, err
+	if output, err = r.Run(cmd); err != nil {
+		return "", err
 	}
 
-	dimensions := strings.Split(Trim(output), " ")
-	if len(dimensions) != 2 {
-		return 0, 0, fmt.Errorf(fmt.Sprintf("expected command to return a string with two elements separated by a space but found '%s'", output))
+	return Trim(output), nil
+}
+
+// A window, as returned by [Runner.ListWindows]
+type Window struct {
+	// The window ID, like "@0"
+	ID string
+
+	// The window's index in its session
+	Index int
+
+	// The window's name
+	Name string
+
+	// The width of the window
+	Width int
+
+	// The height of the window
+	Height int
+
+	// True if this is the active window in its session
+	Active bool
+}
+
+// Returns the windows in the runner's current session
+func (r *Runner) ListWindows() ([]Window, error) {
+	var err error
+
+	var output string
+	var format string = joinFields("#{window_id}", "#{window_index}", "#{window_name}", "#{window_width}", "#{window_height}", "#{window_active}")
+	if output, err = r.Run(fmt.Sprintf("list-windows -F %s", Quote(format))); err != nil {
+		return nil, err
 	}
 
-	var width, height int
-	if width, err = strconv.Atoi(dimensions[0]); err != nil {
-		return 0, 0, err
+	windows := make([]Window, 0)
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		var fields []string
+		if fields, err = splitFields(line, 6); err != nil {
+			return nil, err
+		}
+
+		w := Window{
+			ID:     fields[0],
+			Name:   fields[2],
+			Active: fields[5] == "1",
+		}
+
+		if w.Index, err = strconv.Atoi(fields[1]); err != nil {
+			return nil, fmt.Errorf("error parsing window index of line '%s': '%s'", line, err.Error())
+		}
+		if w.Width, err = strconv.Atoi(fields[3]); err != nil {
+			return nil, fmt.Errorf("error parsing window width of line '%s': '%s'", line, err.Error())
+		}
+		if w.Height, err = strconv.Atoi(fields[4]); err != nil {
+			return nil, fmt.Errorf("error parsing window height of line '%s': '%s'", line, err.Error())
+		}
+
+		windows = append(windows, w)
 	}
-	if height, err = strconv.Atoi(dimensions[1]); err != nil {
-		return 0, 0, err
+
+	return windows, nil
+}
+
+// A window and the session it belongs to, as returned by [Runner.AllWindows]
+type WindowRef struct {
+	// The name of the session the window belongs to
+	Session string
+
+	// The window ID, like "@0"
+	ID string
+
+	// The window's index in its session
+	Index int
+
+	// The window's name
+	Name string
+
+	// True if this is the active window in its session
+	Active bool
+}
+
+// Returns the windows in every session, using a single command. A window
+// linked into more than one session is returned once for each of them.
+func (r *Runner) AllWindows() ([]WindowRef, error) {
+	var err error
+
+	var output string
+	var format string = joinFields("#{session_name}", "#{window_id}", "#{window_index}", "#{window_name}", "#{window_active}")
+	if output, err = r.Run(fmt.Sprintf("list-windows -a -F %s", Quote(format))); err != nil {
+		return nil, err
+	}
+
+	windows := make([]WindowRef, 0)
+
+	lines := strings.Split(Trim(output), "\n")
+	for _, line := range lines {
+		var fields []string
+		if fields, err = splitFields(line, 5); err != nil {
+			return nil, err
+		}
+
+		w := WindowRef{
+			Session: fields[0],
+			ID:      fields[1],
+			Name:    fields[3],
+			Active:  fields[4] == "1",
+		}
+
+		if w.Index, err = strconv.Atoi(fields[2]); err != nil {
+			return nil, fmt.Errorf("error parsing window index of line '%s': '%s'", line, err.Error())
+		}
+
+		windows = append(windows, w)
 	}
 
-	return width, height, nil
+	return windows, nil
+}
+
+// Make the given window the active window in its session
+func (r *Runner) SelectWindow(target string) error {
+	_, err := r.Run(fmt.Sprintf("select-window -t %s", Quote(target)))
+	return err
+}
+
+// Kill the given window and the panes in it. If it is the last window in its
+// session, tmux kills the session too, without reporting an error. Returns the
+// tmux error if there is no such window.
+func (r *Runner) KillWindow(target string) error {
+	_, err := r.Run(fmt.Sprintf("kill-window -t %s", Quote(target)))
+	return err
+}
+
+// Rename the given window. This also turns off the window's
+// automatic-rename option, so tmux doesn't rename it again.
+func (r *Runner) RenameWindow(target, name string) error {
+	var cmd string = fmt.Sprintf("rename-window -t %s %s", Quote(target), Quote(name))
+
+	_, err := r.Run(cmd)
+	return err
+}
+
+// Move the window src to dst, which may be a session, to give the window the
+// next free index there, or an index in a session, like "work:3". If a window
+// already has that index, tmux refuses with an "index in use" error; to insert
+// the window there and shift the others up, use [Runner.SetWindowIndex], or to
+// exchange the two windows, use [Runner.SwapWindow]. The moved window isn't
+// made the active window at its destination, so if it was the active window,
+// tmux picks another.
+func (r *Runner) MoveWindow(src, dst string) error {
+	var cmd string = fmt.Sprintf("move-window -d -s %s -t %s", Quote(src), Quote(dst))
+
+	_, err := r.Run(cmd)
+	return err
+}
+
+// Exchange the positions of windows a and b, which may be in different
+// sessions. The active window of each session stays the same window, even
+// though it now has a different index.
+func (r *Runner) SwapWindow(a, b string) error {
+	var cmd string = fmt.Sprintf("swap-window -d -s %s -t %s", Quote(a), Quote(b))
+
+	_, err := r.Run(cmd)
+	return err
+}
+
+// Move a window to the given index in its session. If another window already
+// has that index, the window is inserted before it, and it and the windows
+// after it are shifted up an index to make room (move-window -b, which
+// requires tmux 3.2 or later); windows aren't swapped. If the window already
+// has the index, nothing is changed.
+func (r *Runner) SetWindowIndex(windowID string, index int) error {
+	var err error
+
+	var output string
+	if output, err = r.displayMessage(windowID, joinFields("#{session_id}", "#{window_index}")); err != nil {
+		return err
+	}
+
+	var fields []string
+	if fields, err = splitFields(output, 2); err != nil {
+		return err
+	}
+	session := fields[0]
+
+	if fields[1] == strconv.Itoa(index) {
+		return nil
+	}
+
+	if output, err = r.Run(fmt.Sprintf("list-windows -t %s -F '#{window_index}'", Quote(session))); err != nil {
+		return err
+	}
+
+	occupied := false
+	for _, line := range strings.Split(Trim(output), "\n") {
+		if line == strconv.Itoa(index) {
+			occupied = true
+			break
+		}
+	}
+
+	var cmd string = "move-window"
+	if occupied {
+		if err = r.requireFeature(FeatureNewWindowBefore, "moving a window before another"); err != nil {
+			return err
+		}
+		cmd += " -b"
+	}
+	cmd += fmt.Sprintf(" -s %s -t %s", Quote(windowID), Quote(fmt.Sprintf("%s:%d", session, index)))
+
+	_, err = r.Run(cmd)
+	return err
+}
+
+// Arrange the panes of the active window using the given layout. This can be
+// the name of one of tmux's preset layouts, like "even-horizontal" or
+// "main-vertical", or a layout string as reported by #{window_layout}.
+func (r *Runner) SelectLayout(layout string) error {
+	window, err := r.GetActiveWindow()
+	if err != nil {
+		return err
+	}
+
+	var cmd string = fmt.Sprintf("select-layout -t %s %s", Quote(window), Quote(layout))
+
+	_, err = r.Run(cmd)
+	return err
 }